@@ -0,0 +1,398 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/client"
+	"go-movie-api/movies/controllers"
+	database "go-movie-api/movies/db"
+	"go-movie-api/movies/middleware"
+	"go-movie-api/movies/openapi"
+	"go-movie-api/movies/repository"
+	"go-movie-api/movies/service"
+	"go-movie-api/movies/tracing"
+	"go-movie-api/movies/validation"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultPort = "8080"
+
+type reachableClient interface {
+	client.Client
+	Reachability(ctx context.Context) map[string]error
+}
+
+// App is the movie API server and everything it owns. It takes over the
+// database pool it is given and closes it on shutdown. The tracer provider
+// stays with the caller, who should shut it down after the app.
+type App struct {
+	config      configs.Config
+	db          *sqlx.DB
+	logger      *slog.Logger
+	tracer      trace.TracerProvider
+	closeClient func()
+	registry    *prometheus.Registry
+	router      *gin.Engine
+	server      *http.Server
+
+	mu        sync.Mutex
+	listener  net.Listener
+	serveErr  chan error
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func New(config configs.Config, db *sqlx.DB, logger *slog.Logger, tracerProvider trace.TracerProvider) (*App, error) {
+	if err := validation.Register(); err != nil {
+		return nil, fmt.Errorf("failed to set up request validation: %w", err)
+	}
+
+	tokens, err := auth.NewTokenManager(config.GetAuthConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up authentication: %w", err)
+	}
+
+	registry := newRegistry(db)
+
+	providerClient, err := client.NewClient(config, logger, tracerProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the movie provider: %w", err)
+	}
+	providerClient = providerClient.Instrument(client.NewProviderMetrics(registry))
+
+	migrator, err := database.NewMigrator(db, logger)
+	if err != nil {
+		providerClient.Close()
+		return nil, err
+	}
+
+	a := &App{
+		config:      config,
+		db:          db,
+		logger:      logger,
+		tracer:      tracerProvider,
+		closeClient: providerClient.Close,
+		registry:    registry,
+		router:      gin.New(),
+		serveErr:    make(chan error, 1),
+	}
+	serverConfig := config.GetServerConfig()
+	// the client IP keys the rate limits, so only proxies we run may set it
+	if err := a.router.SetTrustedProxies(serverConfig.TrustedProxyList()); err != nil {
+		providerClient.Close()
+		return nil, fmt.Errorf("invalid server.trusted_proxies: %w", err)
+	}
+	a.routes(providerClient, migrator, tokens)
+
+	a.server = &http.Server{
+		Handler:           a.router,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+		ReadHeaderTimeout: serverConfig.ReadHeaderTimeout(),
+		ReadTimeout:       serverConfig.ReadTimeout(),
+		WriteTimeout:      serverConfig.WriteTimeout(),
+		IdleTimeout:       serverConfig.IdleTimeout(),
+	}
+
+	return a, nil
+}
+
+func (a *App) routes(providerClient reachableClient, migrator *database.Migrator, tokens *auth.TokenManager) {
+	queryMetrics := repository.NewQueryMetrics(a.registry)
+	movieRepository := repository.NewTracedMovieRepository(
+		repository.NewInstrumentedMovieRepository(repository.NewMovieRepository(a.db, a.logger), queryMetrics), a.tracer)
+	userRespository := repository.NewTracedUserRepository(
+		repository.NewInstrumentedUserRepository(repository.NewUserRepository(a.db, a.logger), queryMetrics), a.tracer)
+	tokenRepository := repository.NewTracedTokenRepository(
+		repository.NewInstrumentedTokenRepository(repository.NewTokenRepository(a.db, a.logger), queryMetrics), a.tracer)
+	apiKeyRepository := repository.NewTracedAPIKeyRepository(
+		repository.NewInstrumentedAPIKeyRepository(repository.NewAPIKeyRepository(a.db, a.logger), queryMetrics), a.tracer)
+
+	breaker := client.NewCircuitBreaker(a.config.GetBreakerConfig(), a.logger)
+	cacheConfig := a.config.GetCacheConfig()
+	var cacheStore client.CacheStore = client.NewMemoryCacheStore(cacheConfig.MaxEntries)
+	if cacheConfig.Store == "postgres" {
+		cacheStore = repository.NewCacheRepository(a.db, cacheConfig.MaxEntries, a.logger)
+	}
+	// cache in front of the breaker so cached movies are still served while the providers are down,
+	// and cache misses for the same movie share one upstream call
+	coalescingClient := client.NewCoalescingClient(client.NewCircuitBreakerClient(providerClient, breaker))
+	cachingClient := client.NewCachingClient(coalescingClient, cacheStore, cacheConfig, a.logger)
+	movieClient := client.NewTracingClient(cachingClient, a.tracer)
+	userService := service.NewUserService(userRespository, a.config.GetAuthConfig().BcryptCost)
+	authService := service.NewAuthService(userRespository, tokenRepository, tokens)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository)
+	movieService := service.NewMovieService(movieClient, movieRepository, a.tracer)
+	moviesController := controllers.NewMoviesController(movieService)
+	userController := controllers.NewUserController(userService)
+	authController := controllers.NewAuthController(authService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	providerController := controllers.NewProviderController(breaker, cachingClient, coalescingClient)
+	docsController := controllers.NewDocsController(openapi.Build())
+	healthController := controllers.NewHealthController(a.logger,
+		databaseCheck(a.db),
+		migrationsCheck(migrator),
+		providerCheck(breaker, providerClient.Reachability, a.logger),
+	)
+
+	router := a.router
+	router.Use(
+		otelgin.Middleware(a.config.GetTracingConfig().ServiceName,
+			otelgin.WithTracerProvider(a.tracer),
+			otelgin.WithPropagators(tracing.Propagator()),
+			otelgin.WithFilter(isTracedRequest),
+		),
+		middleware.RequestID(),
+		middleware.AccessLog(a.logger),
+		middleware.Recovery(a.logger),
+		middleware.Metrics(middleware.NewHttpMetrics(a.registry)),
+		middleware.Errors(),
+	)
+	router.NoRoute(middleware.NoRoute)
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(a.registry, promhttp.HandlerOpts{})))
+	router.GET("/", moviesController.SendMessage)
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+	router.GET("/openapi.json", docsController.GetSpec)
+	router.GET("/docs", docsController.GetSwaggerUI)
+
+	authenticate := middleware.Authenticate(tokens, apiKeyService)
+	// admin goes after authenticate on routes and groups only admins may use
+	admin := middleware.RequireRole(auth.RoleAdmin)
+
+	// rate limits go after authentication, so that they are kept per user or API key
+	rateLimits := a.config.GetRateLimitConfig()
+	rateLimit := func(rule configs.RateLimitRule) gin.HandlerFunc {
+		if !rateLimits.Enabled {
+			return middleware.RateLimit(nil)
+		}
+		return middleware.RateLimit(middleware.NewRateLimiter(rule))
+	}
+	searchLimit := rateLimit(rateLimits.Search)
+	cartLimit := rateLimit(rateLimits.Cart)
+	authLimit := rateLimit(rateLimits.Auth)
+	usersLimit := rateLimit(rateLimits.Users)
+	adminLimit := rateLimit(rateLimits.Admin)
+
+	// the internals of the movie provider are for admins
+	statusGroup := router.Group("/status", authenticate, admin, adminLimit)
+	{
+		statusGroup.GET("/provider", providerController.GetCircuitStatus)
+		statusGroup.GET("/cache", providerController.GetCacheStats)
+		statusGroup.GET("/coalescing", providerController.GetCoalescingStats)
+	}
+
+	authGroup := router.Group("/auth", authLimit)
+	{
+		authGroup.POST("/login", authController.Login)
+		authGroup.POST("/refresh", authController.Refresh)
+		authGroup.POST("/logout", authController.Logout)
+	}
+
+	// /api/v1 replaces the legacy routes, most of which model reads as POSTs
+	deprecated := func(successor string) gin.HandlerFunc {
+		return middleware.Deprecated(legacyRoutesDeprecatedAt, successor)
+	}
+	// the legacy carts are the cart of the user in the path, or of the
+	// authenticated user
+	deprecatedCart := middleware.DeprecatedPerRequest(legacyRoutesDeprecatedAt, func(c *gin.Context) string {
+		userId := c.Param("userId")
+		if userId == "" {
+			userId, _ = auth.UserID(c.Request.Context())
+		}
+		return "/api/v1/users/" + userId + "/cart"
+	})
+
+	usersGroup := router.Group("/users")
+	{
+		usersGroup.POST("/", authLimit, userController.CreateUser)
+		usersGroup.GET("/", authenticate, admin, adminLimit, deprecated("/api/v1/users"), userController.ListUsers)
+	}
+
+	// open to anyone, but API keys need the search scope
+	search := []gin.HandlerFunc{middleware.OptionalAuthenticate(tokens, apiKeyService), middleware.RequireScope(auth.ScopeSearch), searchLimit}
+	cartRead := middleware.RequireScope(auth.ScopeCartRead)
+	cartWrite := middleware.RequireScope(auth.ScopeCartWrite)
+
+	cartRoutes := func(group *gin.RouterGroup) {
+		group.POST("/add", cartWrite, moviesController.AddToMovieCart)
+		group.POST("/list", cartRead, moviesController.GetMoviesInCart)
+		group.POST("/remove", cartWrite, moviesController.RemoveFromMovieCart)
+		group.POST("/clear", cartWrite, moviesController.ClearMovieCart)
+		group.POST("/reorder", cartWrite, moviesController.ReorderMovieCart)
+	}
+	// a user's own cart by id; other users' carts are for admins only
+	cartRoutes(usersGroup.Group("/:userId/cart", authenticate, cartLimit, deprecatedCart))
+
+	moviesGroup := router.Group("/movies")
+	searchGroup := moviesGroup.Group("", search...)
+	searchGroup.Use(deprecated("/api/v1/movies"))
+	{
+		searchGroup.POST("/search", moviesController.SearchMovies)
+		searchGroup.POST("/", moviesController.GetMovieDetails)
+	}
+	cartRoutes(moviesGroup.Group("/cart", authenticate, cartLimit, deprecatedCart))
+
+	v1 := router.Group("/api/v1")
+	v1Movies := v1.Group("/movies", search...)
+	{
+		v1Movies.GET("", middleware.CacheControl(cacheConfig.SearchTTL()), moviesController.ListMovies)
+		v1Movies.GET("/:imdbId", middleware.CacheControl(cacheConfig.DetailsTTL()), moviesController.GetMovie)
+	}
+	v1Cart := v1.Group("/users/:userId/cart", authenticate, cartLimit)
+	{
+		v1Cart.GET("", cartRead, moviesController.GetMoviesInCart)
+		v1Cart.POST("", cartWrite, moviesController.AddToMovieCart)
+		v1Cart.DELETE("", cartWrite, moviesController.ClearMovieCart)
+		v1Cart.PUT("/order", cartWrite, moviesController.ReorderMovieCart)
+		v1Cart.DELETE("/:imdbId", cartWrite, moviesController.RemoveCartMovie)
+	}
+
+	// users manage their own account, admins anyone's
+	userRead := middleware.RequireScope(auth.ScopeUserRead)
+	userWrite := middleware.RequireScope(auth.ScopeUserWrite)
+	v1Users := v1.Group("/users", authenticate)
+	{
+		v1Users.GET("", admin, adminLimit, userController.ListUsers)
+		v1Users.GET("/lookup", admin, adminLimit, userController.LookupUser)
+		v1Users.GET("/:userId", userRead, usersLimit, userController.GetUser)
+		v1Users.PATCH("/:userId", userWrite, usersLimit, userController.UpdateUser)
+		v1Users.DELETE("/:userId", userWrite, usersLimit, userController.DeleteUser)
+		v1Users.POST("/:userId/restore", admin, adminLimit, userController.RestoreUser)
+		// Changing a password checks the current one, so it is limited like a login.
+		v1Users.PUT("/:userId/password", userWrite, authLimit, userController.ChangePassword)
+		v1Users.POST("/:userId/password-reset", admin, adminLimit, userController.ResetPassword)
+	}
+
+	adminGroup := router.Group("/admin", authenticate, admin, adminLimit)
+	{
+		adminGroup.POST("/api-keys", apiKeyController.CreateAPIKey)
+		adminGroup.GET("/api-keys", apiKeyController.ListAPIKeys)
+		adminGroup.DELETE("/api-keys/:id", apiKeyController.RevokeAPIKey)
+	}
+}
+
+// legacyRoutesDeprecatedAt is when the POST routes for reads were superseded
+// by /api/v1. They keep working, but say so in a Deprecation header.
+var legacyRoutesDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// isTracedRequest leaves probes and metric scrapes out of the traces.
+func isTracedRequest(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/metrics", "/healthz", "/readyz":
+		return false
+	}
+	return true
+}
+
+// newRegistry holds the app's metrics, along with the Go runtime, process
+// and connection pool stats.
+func newRegistry(db *sqlx.DB) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		registry.MustRegister(collectors.NewDBStatsCollector(db.DB, "postgres"))
+	}
+	return registry
+}
+
+// Start starts listening on the configured port and serves in the background.
+// Port "0" picks a free port; Addr tells which one.
+func (a *App) Start() error {
+	port := a.config.GetPort()
+	if port == "" {
+		port = defaultPort // if port is not defined in config fallback to default port
+	}
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", port, err)
+	}
+	a.mu.Lock()
+	a.listener = listener
+	a.mu.Unlock()
+
+	a.logger.Info("listening", "addr", listener.Addr().String())
+	go func() {
+		if err := a.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			a.serveErr <- err
+		}
+		close(a.serveErr)
+	}()
+	return nil
+}
+
+// Addr is the address the server listens on once started.
+func (a *App) Addr() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.listener == nil {
+		return ""
+	}
+	return a.listener.Addr().String()
+}
+
+// Run starts the server and blocks until ctx is done or the process gets
+// SIGINT or SIGTERM, then shuts down gracefully.
+func (a *App) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := a.Start(); err != nil {
+		a.close()
+		return err
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("shutting down")
+	case serveErr = <-a.serveErr:
+		a.logger.Error("server stopped", "error", serveErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.GetServerConfig().ShutdownTimeout())
+	defer cancel()
+	return errors.Join(serveErr, a.Shutdown(shutdownCtx))
+}
+
+// Shutdown stops accepting connections, waits for in-flight requests until
+// ctx is done and then closes the outbound client and the database pool.
+func (a *App) Shutdown(ctx context.Context) error {
+	err := a.server.Shutdown(ctx)
+	if err != nil {
+		a.logger.Warn("requests still running at shutdown", "error", err)
+	}
+	return errors.Join(err, a.close())
+}
+
+func (a *App) close() error {
+	a.closeOnce.Do(func() {
+		a.closeClient()
+		if a.db != nil {
+			a.closeErr = a.db.Close()
+		}
+	})
+	return a.closeErr
+}
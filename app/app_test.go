@@ -0,0 +1,548 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go-movie-api/configs"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/openapi"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const testJwtSecret = "test-secret-that-is-at-least-32-bytes"
+
+func newTestApp(t *testing.T) (*App, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	mockDb, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	config := configs.NewConfig()
+	config.Auth.JwtSecret = testJwtSecret
+	config.Port = "0"
+	config.Server.ShutdownTimeoutMs = 1000
+
+	a, err := New(config, sqlx.NewDb(mockDb, "sqlmock"), logging.Discard(), noop.NewTracerProvider())
+	assert.NoError(t, err)
+	return a, mock
+}
+
+func get(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	if !assert.NoError(t, err) {
+		return 0, ""
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestAppServesOnRandomPort(t *testing.T) {
+	a, mock := newTestApp(t)
+	mock.ExpectClose()
+
+	assert.NoError(t, a.Start())
+	assert.NotEmpty(t, a.Addr())
+
+	status, _ := get(t, "http://"+a.Addr()+"/healthz")
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.NoError(t, a.Shutdown(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err := http.Get("http://" + a.Addr() + "/")
+	assert.Error(t, err)
+}
+
+func TestAppDrainsInFlightRequests(t *testing.T) {
+	a, mock := newTestApp(t)
+	mock.ExpectClose()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	a.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.String(http.StatusOK, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- a.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for a.Addr() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	type result struct {
+		status int
+		body   string
+	}
+	slow := make(chan result, 1)
+	go func() {
+		status, body := get(t, "http://"+a.Addr()+"/slow")
+		slow <- result{status, body}
+	}()
+	<-started
+
+	// shutting down waits for the request in flight
+	cancel()
+	select {
+	case <-runErr:
+		t.Fatal("Run returned before the request in flight finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, result{http.StatusOK, "done"}, <-slow)
+	assert.NoError(t, <-runErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppShutdownGivesUpAfterDrainPeriod(t *testing.T) {
+	a, mock := newTestApp(t)
+	mock.ExpectClose()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	a.router.GET("/stuck", func(ctx *gin.Context) {
+		close(started)
+		<-release
+	})
+
+	assert.NoError(t, a.Start())
+	go get(t, "http://"+a.Addr()+"/stuck")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := a.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	// the pool is closed even though a request didn't finish
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewRejectsInvalidProviderConfig(t *testing.T) {
+	config := configs.NewConfig()
+	config.Auth.JwtSecret = testJwtSecret
+	config.Providers.Primary = "imdb"
+
+	_, err := New(config, nil, logging.Discard(), noop.NewTracerProvider())
+
+	assert.EqualError(t, err, `failed to set up the movie provider: unknown movie provider "imdb"`)
+}
+
+func TestNewRequiresJwtSecret(t *testing.T) {
+	_, err := New(configs.NewConfig(), nil, logging.Discard(), noop.NewTracerProvider())
+
+	assert.EqualError(t, err, "failed to set up authentication: auth.jwt_secret must be at least 32 bytes")
+}
+
+func TestAppReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	omdb := httptest.NewServer(http.NotFoundHandler())
+	defer omdb.Close()
+
+	mockDb, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	defer mockDb.Close()
+	// the checks run concurrently
+	mock.MatchExpectationsInOrder(false)
+
+	config := configs.NewConfig()
+	config.Auth.JwtSecret = testJwtSecret
+	config.MoviesListUrl = omdb.URL
+	a, err := New(config, sqlx.NewDb(mockDb, "sqlmock"), logging.Discard(), noop.NewTracerProvider())
+	assert.NoError(t, err)
+
+	versionQuery := regexp.QuoteMeta(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`)
+
+	readyz := func() (int, model.HealthReport) {
+		resp := httptest.NewRecorder()
+		a.router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var report model.HealthReport
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &report))
+		return resp.Code, report
+	}
+
+	t.Run("should be ready when the database is migrated", func(t *testing.T) {
+		mock.ExpectPing()
+		mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(11))
+
+		status, report := readyz()
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, model.HealthOK, report.Status)
+		assert.Equal(t, map[string]any{"version": float64(11), "latest": float64(11)}, report.Checks["migrations"].Details)
+		assert.Equal(t, "closed", report.Checks["movieProvider"].Details["circuit"])
+		assert.Equal(t, map[string]any{"omdb": "reachable"}, report.Checks["movieProvider"].Details["providers"])
+	})
+
+	t.Run("should not be ready with pending migrations", func(t *testing.T) {
+		mock.ExpectPing()
+		mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+
+		status, report := readyz()
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "database is at version 4, expected 11", report.Checks["migrations"].Error)
+	})
+
+	t.Run("should not be ready without the database", func(t *testing.T) {
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		mock.ExpectQuery(versionQuery).WillReturnError(errors.New("connection refused"))
+
+		status, report := readyz()
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, model.HealthUnavailable, report.Checks["database"].Status)
+	})
+
+	resp := httptest.NewRecorder()
+	a.router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAppRestrictsUserListingToAdmins(t *testing.T) {
+	a, mock := newTestApp(t)
+
+	tokens, err := auth.NewTokenManager(a.config.GetAuthConfig())
+	assert.NoError(t, err)
+
+	listUsers := func(role string) int {
+		access, err := tokens.IssueAccess("7", role)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+		req.Header.Set("Authorization", "Bearer "+access.Value)
+		resp := httptest.NewRecorder()
+		a.router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, listUsers(auth.RoleUser))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_name, email, country, role`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "email", "country", "role", "created_at", "updated_at", "deleted_at"}))
+	assert.Equal(t, http.StatusOK, listUsers(auth.RoleAdmin))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppRestrictsStatusToAdmins(t *testing.T) {
+	a, _ := newTestApp(t)
+
+	tokens, err := auth.NewTokenManager(a.config.GetAuthConfig())
+	assert.NoError(t, err)
+
+	for _, path := range []string{"/status/provider", "/status/cache", "/status/coalescing"} {
+		getStatus := func(role string) int {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if role != "" {
+				access, err := tokens.IssueAccess("7", role)
+				assert.NoError(t, err)
+				req.Header.Set("Authorization", "Bearer "+access.Value)
+			}
+			resp := httptest.NewRecorder()
+			a.router.ServeHTTP(resp, req)
+			return resp.Code
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, getStatus(""), path)
+		assert.Equal(t, http.StatusForbidden, getStatus(auth.RoleUser), path)
+		assert.Equal(t, http.StatusOK, getStatus(auth.RoleAdmin), path)
+	}
+}
+
+func TestAppLetsUsersManageOnlyTheirOwnAccount(t *testing.T) {
+	a, mock := newTestApp(t)
+
+	tokens, err := auth.NewTokenManager(a.config.GetAuthConfig())
+	assert.NoError(t, err)
+
+	const self, other = "0b7e4c1e-5f6a-4d0b-9a51-3c2f8e1d7a10", "5d2c9b8a-1e3f-4a7b-8c6d-9e0f1a2b3c4d"
+	getUser := func(userId string) int {
+		access, err := tokens.IssueAccess(self, auth.RoleUser)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+userId, nil)
+		req.Header.Set("Authorization", "Bearer "+access.Value)
+		resp := httptest.NewRecorder()
+		a.router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, getUser(other))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_name, email, country, role, created_at, updated_at, deleted_at FROM users WHERE id = $1`)).
+		WithArgs(self).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "email", "country", "role", "created_at", "updated_at", "deleted_at"}).
+			AddRow(self, "Jane", "jane@example.com", "IN", "user", "2026-10-01T00:00:00Z", "2026-10-01T00:00:00Z", nil))
+	assert.Equal(t, http.StatusOK, getUser(self))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppLimitsUserRoutesApartFromLogins(t *testing.T) {
+	a, _ := newTestApp(t)
+
+	tokens, err := auth.NewTokenManager(a.config.GetAuthConfig())
+	assert.NoError(t, err)
+	access, err := tokens.IssueAccess("0b7e4c1e-5f6a-4d0b-9a51-3c2f8e1d7a10", auth.RoleUser)
+	assert.NoError(t, err)
+
+	// more than the burst of the auth limit, well within the users one
+	for range a.config.GetRateLimitConfig().Auth.Burst + 1 {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/5d2c9b8a-1e3f-4a7b-8c6d-9e0f1a2b3c4d", nil)
+		req.Header.Set("Authorization", "Bearer "+access.Value)
+		resp := httptest.NewRecorder()
+		a.router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	}
+}
+
+func TestAppLetsUsersSetPasswords(t *testing.T) {
+	a, mock := newTestApp(t)
+
+	tokens, err := auth.NewTokenManager(a.config.GetAuthConfig())
+	assert.NoError(t, err)
+
+	const self, other = "0b7e4c1e-5f6a-4d0b-9a51-3c2f8e1d7a10", "5d2c9b8a-1e3f-4a7b-8c6d-9e0f1a2b3c4d"
+	call := func(role, method, path, body string) int {
+		access, err := tokens.IssueAccess(self, role)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+access.Value)
+		resp := httptest.NewRecorder()
+		a.router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+	setPassword := regexp.QuoteMeta(`UPDATE users SET password_hash = $2 WHERE id = $1 AND deleted_at IS NULL`)
+
+	// a user who signed up before passwords existed sets their first one
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM users WHERE id = $1`)).WithArgs(self).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "email", "country", "role", "created_at", "updated_at", "deleted_at"}).
+			AddRow(self, "Jane", "jane@example.com", "IN", "user", "2026-10-01T00:00:00Z", "2026-10-01T00:00:00Z", nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`COALESCE(password_hash, '') AS password_hash`)).WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password_hash", "role"}).AddRow(self, "", "user"))
+	mock.ExpectExec(setPassword).WithArgs(self, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.Equal(t, http.StatusOK, call(auth.RoleUser, http.MethodPut, "/api/v1/users/"+self+"/password", `{"password":"correct horse"}`))
+
+	// but not anyone else's, even as an admin
+	assert.Equal(t, http.StatusForbidden, call(auth.RoleAdmin, http.MethodPut, "/api/v1/users/"+other+"/password", `{"password":"correct horse"}`))
+
+	// which only an admin can reset
+	assert.Equal(t, http.StatusForbidden, call(auth.RoleUser, http.MethodPost, "/api/v1/users/"+other+"/password-reset", `{"password":"correct horse"}`))
+	assert.Equal(t, http.StatusBadRequest, call(auth.RoleAdmin, http.MethodPost, "/api/v1/users/"+other+"/password-reset", `{"password":"short"}`))
+	mock.ExpectExec(setPassword).WithArgs(other, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.Equal(t, http.StatusOK, call(auth.RoleAdmin, http.MethodPost, "/api/v1/users/"+other+"/password-reset", `{"password":"correct horse"}`))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppAuthenticatesAPIKeys(t *testing.T) {
+	a, mock := newTestApp(t)
+
+	keyQuery := regexp.QuoteMeta(`FROM api_keys WHERE key_hash = $1`)
+	keyRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "prefix", "scopes", "created_at", "last_used_at", "revoked_at"}).
+			AddRow("key-1", "7", "partner", "mvk_abcdefgh", "{cart:read}", time.Now(), nil, nil)
+	}
+	call := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"movieId":"tt1375666"}`))
+		req.Header.Set("X-API-Key", "mvk_partner")
+		resp := httptest.NewRecorder()
+		a.router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	mock.ExpectQuery(keyQuery).WithArgs(auth.HashAPIKey("mvk_partner")).WillReturnRows(keyRow())
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE api_keys SET last_used_at`)).WithArgs("key-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM movies_cart WHERE user_id = $1`)).WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "imdb_id", "year", "genre", "actors", "type", "poster", "position"}))
+	assert.Equal(t, http.StatusOK, call("/movies/cart/list"))
+
+	// the key can read the cart but not change it
+	mock.ExpectQuery(keyQuery).WillReturnRows(keyRow())
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE api_keys SET last_used_at`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Equal(t, http.StatusForbidden, call("/movies/cart/add"))
+
+	// and isn't an admin, whoever owns it
+	mock.ExpectQuery(keyQuery).WillReturnRows(keyRow())
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE api_keys SET last_used_at`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Equal(t, http.StatusForbidden, call("/admin/api-keys"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppServesV1CartRoutes(t *testing.T) {
+	a, mock := newTestApp(t)
+
+	tokens, err := auth.NewTokenManager(a.config.GetAuthConfig())
+	assert.NoError(t, err)
+	const self, other = "0b7e4c1e-5f6a-4d0b-9a51-3c2f8e1d7a10", "5d2c9b8a-1e3f-4a7b-8c6d-9e0f1a2b3c4d"
+	access, err := tokens.IssueAccess(self, auth.RoleUser)
+	assert.NoError(t, err)
+
+	call := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+access.Value)
+		resp := httptest.NewRecorder()
+		a.router.ServeHTTP(resp, req)
+		return resp
+	}
+	cartQuery := regexp.QuoteMeta(`FROM movies_cart WHERE user_id = $1`)
+	cartRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "title", "imdb_id", "year", "genre", "actors", "type", "poster", "position"})
+	}
+
+	mock.ExpectQuery(cartQuery).WithArgs(self).WillReturnRows(cartRows())
+	resp := call(http.MethodGet, "/api/v1/users/"+self+"/cart")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get("Deprecation"))
+
+	mock.ExpectQuery(cartQuery).WithArgs(self).WillReturnRows(cartRows())
+	resp = call(http.MethodPost, "/movies/cart/list")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "@1792281600", resp.Header().Get("Deprecation"))
+	assert.Equal(t, "</api/v1/users/"+self+`/cart>; rel="successor-version"`, resp.Header().Get("Link"))
+
+	resp = call(http.MethodDelete, "/api/v1/users/"+other+"/cart/tt1375666")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = call(http.MethodDelete, "/api/v1/users/"+self+"/cart/1375666")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = call(http.MethodGet, "/api/v1/users/7/cart")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppDocumentsEveryRoute(t *testing.T) {
+	a, _ := newTestApp(t)
+
+	resp := httptest.NewRecorder()
+	a.router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var spec openapi.Document
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &spec))
+
+	registered := map[string]bool{}
+	for _, route := range a.router.Routes() {
+		path, method := openapi.Path(route.Path), strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+		assert.Contains(t, spec.Paths[path], method, "%s %s is missing from the OpenAPI document", route.Method, route.Path)
+	}
+	for path, item := range spec.Paths {
+		for method := range item {
+			assert.True(t, registered[method+" "+path], "the OpenAPI document has %s %s, which is not a route", method, path)
+		}
+	}
+}
+
+func TestAppExposesMetrics(t *testing.T) {
+	a, _ := newTestApp(t)
+
+	a.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	resp := httptest.NewRecorder()
+	a.router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `http_requests_total{method="GET",route="/healthz",status="200"} 1`)
+	assert.Contains(t, resp.Body.String(), `go_sql_open_connections{db_name="postgres"}`)
+}
+
+func TestAppTracesAddToCart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var traceparent string
+	omdb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = io.WriteString(w, `{"Title":"Inception","Year":"2010","imdbID":"tt1375666","Type":"movie","Response":"True"}`)
+	}))
+	defer omdb.Close()
+
+	mockDb, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDb.Close()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).WithArgs("7", "tt1375666").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO movies_cart`)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	recorder := tracetest.NewSpanRecorder()
+	config := configs.NewConfig()
+	config.Auth.JwtSecret = testJwtSecret
+	config.MoviesListUrl = omdb.URL
+	a, err := New(config, sqlx.NewDb(mockDb, "sqlmock"), logging.Discard(), sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	assert.NoError(t, err)
+
+	tokens, err := auth.NewTokenManager(config.GetAuthConfig())
+	assert.NoError(t, err)
+	access, err := tokens.IssueAccess("7", auth.RoleUser)
+	assert.NoError(t, err)
+
+	// the cart belongs to the token's user, whatever the body says
+	req := httptest.NewRequest(http.MethodPost, "/movies/cart/add", strings.NewReader(`{"movieId":"tt1375666","userId":"1"}`))
+	req.Header.Set("Authorization", "Bearer "+access.Value)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp := httptest.NewRecorder()
+	a.router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), span.Name())
+	}
+
+	parents := map[string]string{
+		"movieService.AddMovieToCart":    "/movies/cart/add",
+		"movieRepository.IsMovieInCart":  "movieService.AddMovieToCart",
+		"client.GetMovieDetailsById":     "movieService.AddMovieToCart",
+		"GET":                            "client.GetMovieDetailsById",
+		"movieRepository.AddToMovieCart": "movieService.AddMovieToCart",
+	}
+	for name, parent := range parents {
+		if assert.Contains(t, spans, name) && assert.Contains(t, spans, parent) {
+			assert.Equal(t, spans[parent].SpanContext().SpanID(), spans[name].Parent().SpanID(), name)
+		}
+	}
+
+	assert.Contains(t, traceparent, spans["GET"].SpanContext().SpanID().String())
+	for _, attr := range spans["GET"].Attributes() {
+		if attr.Key == "url.full" {
+			assert.Contains(t, attr.Value.AsString(), "apikey=REDACTED")
+		}
+	}
+}
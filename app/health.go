@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/client"
+	"go-movie-api/movies/controllers"
+	database "go-movie-api/movies/db"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func databaseCheck(db *sqlx.DB) controllers.HealthCheck {
+	return controllers.HealthCheck{
+		Name:     "database",
+		Critical: true,
+		Check: func(ctx context.Context) (map[string]any, error) {
+			stats := db.Stats()
+			details := map[string]any{"openConnections": stats.OpenConnections, "inUse": stats.InUse}
+			return details, db.PingContext(ctx)
+		},
+	}
+}
+
+func migrationsCheck(migrator *database.Migrator) controllers.HealthCheck {
+	return controllers.HealthCheck{
+		Name:     "migrations",
+		Critical: true,
+		Check: func(ctx context.Context) (map[string]any, error) {
+			version, err := migrator.Version(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			details := map[string]any{"version": version, "latest": migrator.Latest()}
+			if version < migrator.Latest() {
+				return details, apperror.Unavailable("migrations_pending", fmt.Sprintf("database is at version %d, expected %d", version, migrator.Latest()))
+			}
+			return details, nil
+		},
+	}
+}
+
+// providerCheck isn't critical: carts can still be served while the movie
+// providers are down.
+func providerCheck(breaker *client.CircuitBreaker, reachability func(ctx context.Context) map[string]error, logger *slog.Logger) controllers.HealthCheck {
+	return controllers.HealthCheck{
+		Name: "movieProvider",
+		Check: func(ctx context.Context) (map[string]any, error) {
+			circuit := breaker.Status()
+			providers := map[string]string{}
+			details := map[string]any{"circuit": circuit.State, "providers": providers}
+
+			reachable := 0
+			for name, err := range reachability(ctx) {
+				if err != nil {
+					logger.WarnContext(ctx, "movie provider is unreachable", "provider", name, "error", err)
+					providers[name] = "unreachable"
+					continue
+				}
+				providers[name] = "reachable"
+				reachable++
+			}
+
+			if circuit.State == client.CircuitOpen {
+				details["retryAfterSeconds"] = circuit.RetryAfterSeconds
+				return details, apperror.Unavailable("circuit_open", "circuit is open")
+			}
+			if reachable == 0 {
+				return details, apperror.Unavailable("providers_unreachable", "no movie provider can be reached")
+			}
+			return details, nil
+		},
+	}
+}
@@ -1,58 +1,146 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
 
+	"go-movie-api/app"
 	"go-movie-api/configs"
-	"go-movie-api/movies/client"
 	"go-movie-api/movies/constants"
 	db "go-movie-api/movies/db"
+	"go-movie-api/movies/logging"
 	"go-movie-api/movies/repository"
-	"go-movie-api/movies/service"
-
-	"go-movie-api/movies/controllers"
-
-	"github.com/gin-gonic/gin"
+	"go-movie-api/movies/tracing"
 )
 
 func main() {
-	router := gin.Default()
+	// "migrate up|down [steps]|version" runs migrations instead of the server
+	args := os.Args[1:]
+	var migrateArgs []string
+	if len(args) > 0 && args[0] == "migrate" {
+		migrateArgs, args = splitCommand(args[1:])
+	}
+
+	config, err := configs.Load(constants.ConfigFilePath, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	logger, err := logging.New(config.GetLogConfig(), os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up logging: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
 
-	config := configs.NewConfig()
-	configs.LoadConfig(config, constants.ConfigFilePath)
-	dbInstance := db.InitDB()
-	movieRepository := repository.NewMovieRepository(dbInstance)
-	userRespository := repository.NewUserRepository(dbInstance)
+	tracerProvider, err := tracing.NewProvider(context.Background(), config.GetTracingConfig())
+	if err != nil {
+		fatal(logger, "failed to set up tracing", err)
+	}
+	defer func() {
+		if err := tracerProvider.Shutdown(context.Background()); err != nil {
+			logger.Error("failed to flush traces", "error", err)
+		}
+	}()
 
-	client := client.NewClient(config)
-	userService := service.NewUserService(userRespository)
-	movieService := service.NewMovieService(client, movieRepository)
-	moviesController := controllers.NewMoviesController(movieService)
-	userController := controllers.NewUserController(userService)
+	dbInstance, err := db.InitDB(context.Background(), config.GetDatabaseConfig(), logger)
+	if err != nil {
+		fatal(logger, "failed to connect to the database", err)
+	}
 
-	router.GET("/", moviesController.SendMessage)
-	port := config.GetPort()
+	migrator, err := db.NewMigrator(dbInstance, logger)
+	if err != nil {
+		fatal(logger, "failed to load migrations", err)
+	}
+	if migrateArgs != nil {
+		defer dbInstance.Close()
+		if err := runMigrations(context.Background(), migrator, migrateArgs); err != nil {
+			fatal(logger, "migration failed", err)
+		}
+		return
+	}
+	if config.GetDatabaseConfig().MigrateOnStart {
+		if err := migrator.Up(context.Background()); err != nil {
+			fatal(logger, "migration failed", err)
+		}
+	}
 
-	usersGroup := router.Group("/users")
-	{
-		usersGroup.POST("/", userController.CreateUser)
-		usersGroup.GET("/", userController.GetUsers)
+	if email := config.GetAuthConfig().BootstrapAdminEmail; email != "" {
+		if err := bootstrapAdmin(context.Background(), repository.NewUserRepository(dbInstance, logger), email, logger); err != nil {
+			fatal(logger, "failed to bootstrap the first admin", err)
+		}
 	}
 
-	moviesGroup := router.Group("/movies")
-	{
-		moviesGroup.POST("/search", moviesController.SearchMovies)
-		moviesGroup.POST("/", moviesController.GetMovieDetails)
-		moviesGroup.POST("/cart/add", moviesController.AddToMovieCart)
-		moviesGroup.POST("/cart/list", moviesController.GetMoviesInCart)
+	application, err := app.New(config, dbInstance, logger, tracerProvider)
+	if err != nil {
+		fatal(logger, "failed to set up the app", err)
 	}
 
-	if port == "" {
-		port = "8080" // if port is not defined in config fallback to default port
+	if err := application.Run(context.Background()); err != nil {
+		fatal(logger, "failed to run server", err)
+	}
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// bootstrapAdmin makes the user with the given email an admin if there is no
+// admin yet, so a fresh install can be managed. Once there is an admin it
+// does nothing.
+func bootstrapAdmin(ctx context.Context, users repository.UserRespository, email string, logger *slog.Logger) error {
+	promoted, err := users.PromoteFirstAdmin(ctx, email)
+	if err != nil {
+		return err
+	}
+	if promoted {
+		logger.Info("promoted the bootstrap user to admin")
+	}
+	return nil
+}
+
+// splitCommand separates the words of a command from the flags after them.
+func splitCommand(args []string) (words []string, flags []string) {
+	words = []string{}
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		words = append(words, args[0])
+		args = args[1:]
+	}
+	return words, args
+}
+
+func runMigrations(ctx context.Context, migrator *db.Migrator, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
 	}
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
-		panic(err)
+	switch command {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		return migrator.Down(ctx, steps)
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("database is at version %d of %d\n", version, migrator.Latest())
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or version", command)
 	}
 }
@@ -1,25 +1,243 @@
 package configs
 
 import (
-	"encoding/json"
-	"log"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type config struct {
-	Port          string `json:"port"`
-	ApiKey        string `json:"api_key"`
-	MoviesListUrl string `json:"get_movie_list_url"`
+	Port          string           `json:"port"`
+	ApiKey        string           `json:"api_key"`
+	MoviesListUrl string           `json:"get_movie_list_url"`
+	HttpClient    HttpClientConfig `json:"http_client"`
+	Breaker       BreakerConfig    `json:"circuit_breaker"`
+	Cache         CacheConfig      `json:"cache"`
+	Providers     ProvidersConfig  `json:"providers"`
+	Database      DatabaseConfig   `json:"database"`
+	Server        ServerConfig     `json:"server"`
+	Log           LogConfig        `json:"log"`
+	Tracing       TracingConfig    `json:"tracing"`
+	Auth          AuthConfig       `json:"auth"`
+	RateLimit     RateLimitConfig  `json:"rate_limit"`
+}
+
+// HttpClientConfig controls timeouts and retries for calls to the movie provider.
+type HttpClientConfig struct {
+	ConnectTimeoutMs int `json:"connect_timeout_ms"`
+	ReadTimeoutMs    int `json:"read_timeout_ms"`
+	MaxRetries       int `json:"max_retries"`
+	RetryBaseDelayMs int `json:"retry_base_delay_ms"`
+	RetryMaxDelayMs  int `json:"retry_max_delay_ms"`
+}
+
+// BreakerConfig controls when calls to the movie provider are short-circuited.
+type BreakerConfig struct {
+	FailureThreshold    int `json:"failure_threshold"`
+	CoolDownMs          int `json:"cool_down_ms"`
+	HalfOpenMaxRequests int `json:"half_open_max_requests"`
+}
+
+// CacheConfig controls caching of movie provider responses. Store is either
+// "memory" or "postgres".
+type CacheConfig struct {
+	Store              string `json:"store"`
+	MaxEntries         int    `json:"max_entries"`
+	SearchTTLSeconds   int    `json:"search_ttl_seconds"`
+	DetailsTTLSeconds  int    `json:"details_ttl_seconds"`
+	NegativeTTLSeconds int    `json:"negative_ttl_seconds"`
+}
+
+// ProvidersConfig selects the movie metadata providers, "omdb" or "tmdb".
+// Fallback is optional and is only tried when the primary provider fails.
+// OmdbDailyBudget caps the requests made to OMDb per UTC day, retries
+// included; 0 means no cap.
+type ProvidersConfig struct {
+	Primary         string     `json:"primary"`
+	Fallback        string     `json:"fallback"`
+	OmdbDailyBudget int        `json:"omdb_daily_budget"`
+	Tmdb            TmdbConfig `json:"tmdb"`
+}
+
+type TmdbConfig struct {
+	ApiKey       string `json:"api_key"`
+	BaseUrl      string `json:"base_url"`
+	ImageBaseUrl string `json:"image_base_url"`
+}
+
+// DatabaseConfig holds the Postgres connection, pool and startup retry settings.
+// MigrateOnStart applies pending migrations before the server starts.
+type DatabaseConfig struct {
+	Host                   string `json:"host"`
+	Port                   int    `json:"port"`
+	User                   string `json:"user"`
+	Password               string `json:"password"`
+	Name                   string `json:"name"`
+	SSLMode                string `json:"sslmode"`
+	MaxOpenConns           int    `json:"max_open_conns"`
+	MaxIdleConns           int    `json:"max_idle_conns"`
+	ConnMaxLifetimeSeconds int    `json:"conn_max_lifetime_seconds"`
+	ConnMaxIdleTimeSeconds int    `json:"conn_max_idle_time_seconds"`
+	ConnectRetries         int    `json:"connect_retries"`
+	RetryBaseDelayMs       int    `json:"retry_base_delay_ms"`
+	RetryMaxDelayMs        int    `json:"retry_max_delay_ms"`
+	MigrateOnStart         bool   `json:"migrate_on_start"`
+}
+
+// ServerConfig holds the http server timeouts. ShutdownTimeoutMs is how long
+// in-flight requests get to finish after SIGINT/SIGTERM. TrustedProxies is a
+// comma separated list of the IPs or CIDRs of proxies whose X-Forwarded-For
+// is believed; with none the client is the peer address.
+type ServerConfig struct {
+	ReadHeaderTimeoutMs int    `json:"read_header_timeout_ms"`
+	ReadTimeoutMs       int    `json:"read_timeout_ms"`
+	WriteTimeoutMs      int    `json:"write_timeout_ms"`
+	IdleTimeoutMs       int    `json:"idle_timeout_ms"`
+	ShutdownTimeoutMs   int    `json:"shutdown_timeout_ms"`
+	TrustedProxies      string `json:"trusted_proxies"`
+}
+
+// LogConfig sets the minimum log level (debug, info, warn or error) and the
+// output format, json or text.
+type LogConfig struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// TracingConfig selects where spans are exported: "none", "stdout" or "otlp".
+// Endpoint is the host:port of an OTLP/HTTP collector; when empty the
+// standard OTEL_EXPORTER_OTLP_* variables apply. SamplePercent is the share
+// of new traces that are recorded.
+type TracingConfig struct {
+	Exporter      string `json:"exporter"`
+	Endpoint      string `json:"endpoint"`
+	Insecure      bool   `json:"insecure"`
+	ServiceName   string `json:"service_name"`
+	SamplePercent int    `json:"sample_percent"`
+}
+
+// AuthConfig controls the JWTs handed out at login. JwtSecret signs them and
+// has to be at least 32 bytes; set it through MOVIE_API_AUTH_JWT_SECRET.
+// The user with BootstrapAdminEmail is made an admin at startup while there
+// is no admin yet.
+type AuthConfig struct {
+	JwtSecret              string `json:"jwt_secret"`
+	Issuer                 string `json:"issuer"`
+	AccessTokenTTLSeconds  int    `json:"access_token_ttl_seconds"`
+	RefreshTokenTTLSeconds int    `json:"refresh_token_ttl_seconds"`
+	BcryptCost             int    `json:"bcrypt_cost"`
+	BootstrapAdminEmail    string `json:"bootstrap_admin_email"`
+}
+
+// RateLimitConfig sets the request limits of each route group. Every user,
+// API key or, for anonymous requests, IP address gets its own limit.
+type RateLimitConfig struct {
+	Enabled bool          `json:"enabled"`
+	Search  RateLimitRule `json:"search"`
+	Cart    RateLimitRule `json:"cart"`
+	Auth    RateLimitRule `json:"auth"`
+	Users   RateLimitRule `json:"users"`
+	Admin   RateLimitRule `json:"admin"`
+}
+
+// RateLimitRule is a token bucket: Burst requests at once, refilled at
+// RequestsPerMinute. A rule without a rate doesn't limit.
+type RateLimitRule struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	Burst             int `json:"burst"`
 }
 
 type Config interface {
 	GetPort() string
 	GetApiKey() string
 	SearchMoviesUrl() string
+	GetHttpClientConfig() HttpClientConfig
+	GetBreakerConfig() BreakerConfig
+	GetCacheConfig() CacheConfig
+	GetProvidersConfig() ProvidersConfig
+	GetDatabaseConfig() DatabaseConfig
+	GetServerConfig() ServerConfig
+	GetLogConfig() LogConfig
+	GetTracingConfig() TracingConfig
+	GetAuthConfig() AuthConfig
+	GetRateLimitConfig() RateLimitConfig
 }
 
 func NewConfig() *config {
-	return &config{}
+	return &config{
+		HttpClient: HttpClientConfig{
+			ConnectTimeoutMs: 2000,
+			ReadTimeoutMs:    5000,
+			MaxRetries:       2,
+			RetryBaseDelayMs: 100,
+			RetryMaxDelayMs:  2000,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold:    5,
+			CoolDownMs:          30000,
+			HalfOpenMaxRequests: 1,
+		},
+		Cache: CacheConfig{
+			Store:              "memory",
+			MaxEntries:         1000,
+			SearchTTLSeconds:   600,
+			DetailsTTLSeconds:  86400,
+			NegativeTTLSeconds: 300,
+		},
+		Providers: ProvidersConfig{
+			Primary:         "omdb",
+			OmdbDailyBudget: 950,
+			Tmdb: TmdbConfig{
+				BaseUrl:      "https://api.themoviedb.org/3",
+				ImageBaseUrl: "https://image.tmdb.org/t/p/w500",
+			},
+		},
+		Database: DatabaseConfig{
+			Host:                   "localhost",
+			Port:                   5432,
+			User:                   "postgres",
+			Name:                   "postgres",
+			SSLMode:                "disable",
+			MaxOpenConns:           25,
+			MaxIdleConns:           5,
+			ConnMaxLifetimeSeconds: 1800,
+			ConnMaxIdleTimeSeconds: 300,
+			ConnectRetries:         5,
+			RetryBaseDelayMs:       500,
+			RetryMaxDelayMs:        5000,
+			MigrateOnStart:         true,
+		},
+		Server: ServerConfig{
+			ReadHeaderTimeoutMs: 5000,
+			ReadTimeoutMs:       10000,
+			WriteTimeoutMs:      30000,
+			IdleTimeoutMs:       120000,
+			ShutdownTimeoutMs:   20000,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:      "none",
+			ServiceName:   "go-movie-api",
+			SamplePercent: 100,
+		},
+		Auth: AuthConfig{
+			Issuer:                 "go-movie-api",
+			AccessTokenTTLSeconds:  900,
+			RefreshTokenTTLSeconds: 604800,
+			BcryptCost:             12,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Search:  RateLimitRule{RequestsPerMinute: 60, Burst: 20},
+			Cart:    RateLimitRule{RequestsPerMinute: 120, Burst: 30},
+			Auth:    RateLimitRule{RequestsPerMinute: 10, Burst: 5},
+			Users:   RateLimitRule{RequestsPerMinute: 60, Burst: 20},
+			Admin:   RateLimitRule{RequestsPerMinute: 60, Burst: 20},
+		},
+	}
 }
 
 func (c *config) GetPort() string {
@@ -34,16 +252,158 @@ func (c *config) SearchMoviesUrl() string {
 	return c.MoviesListUrl
 }
 
-func LoadConfig(config *config, path string) {
-	file, err := os.Open(path)
-	if err != nil {
-		log.Fatal("Could not open the config.json", err)
-		panic(err)
+func (c *config) GetHttpClientConfig() HttpClientConfig {
+	return c.HttpClient
+}
+
+func (c *config) GetBreakerConfig() BreakerConfig {
+	return c.Breaker
+}
+
+func (c *config) GetCacheConfig() CacheConfig {
+	return c.Cache
+}
+
+func (c *config) GetProvidersConfig() ProvidersConfig {
+	return c.Providers
+}
+
+func (c *config) GetDatabaseConfig() DatabaseConfig {
+	return c.Database
+}
+
+func (c *config) GetServerConfig() ServerConfig {
+	return c.Server
+}
+
+func (c *config) GetLogConfig() LogConfig {
+	return c.Log
+}
+
+func (c *config) GetTracingConfig() TracingConfig {
+	return c.Tracing
+}
+
+func (c *config) GetAuthConfig() AuthConfig {
+	return c.Auth
+}
+
+func (c *config) GetRateLimitConfig() RateLimitConfig {
+	return c.RateLimit
+}
+
+func (h HttpClientConfig) ConnectTimeout() time.Duration {
+	return time.Duration(h.ConnectTimeoutMs) * time.Millisecond
+}
+
+func (h HttpClientConfig) ReadTimeout() time.Duration {
+	return time.Duration(h.ReadTimeoutMs) * time.Millisecond
+}
+
+func (h HttpClientConfig) RetryBaseDelay() time.Duration {
+	return time.Duration(h.RetryBaseDelayMs) * time.Millisecond
+}
+
+func (h HttpClientConfig) RetryMaxDelay() time.Duration {
+	return time.Duration(h.RetryMaxDelayMs) * time.Millisecond
+}
+
+func (b BreakerConfig) CoolDown() time.Duration {
+	return time.Duration(b.CoolDownMs) * time.Millisecond
+}
+
+func (c CacheConfig) SearchTTL() time.Duration {
+	return time.Duration(c.SearchTTLSeconds) * time.Second
+}
+
+func (c CacheConfig) DetailsTTL() time.Duration {
+	return time.Duration(c.DetailsTTLSeconds) * time.Second
+}
+
+func (c CacheConfig) NegativeTTL() time.Duration {
+	return time.Duration(c.NegativeTTLSeconds) * time.Second
+}
+
+// DSN returns the settings as a libpq connection string.
+func (d DatabaseConfig) DSN() string {
+	params := []struct{ key, value string }{
+		{"host", d.Host},
+		{"port", strconv.Itoa(d.Port)},
+		{"user", d.User},
+		{"password", d.Password},
+		{"dbname", d.Name},
+		{"sslmode", d.SSLMode},
+	}
+
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		if param.value != "" {
+			parts = append(parts, param.key+"="+quoteDSNValue(param.value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (d DatabaseConfig) ConnMaxLifetime() time.Duration {
+	return time.Duration(d.ConnMaxLifetimeSeconds) * time.Second
+}
+
+func (d DatabaseConfig) ConnMaxIdleTime() time.Duration {
+	return time.Duration(d.ConnMaxIdleTimeSeconds) * time.Second
+}
+
+func (d DatabaseConfig) RetryBaseDelay() time.Duration {
+	return time.Duration(d.RetryBaseDelayMs) * time.Millisecond
+}
+
+func (d DatabaseConfig) RetryMaxDelay() time.Duration {
+	return time.Duration(d.RetryMaxDelayMs) * time.Millisecond
+}
+
+// quoteDSNValue quotes values that libpq would otherwise split or misread.
+func quoteDSNValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
 	}
-	defer file.Close()
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func (a AuthConfig) AccessTokenTTL() time.Duration {
+	return time.Duration(a.AccessTokenTTLSeconds) * time.Second
+}
+
+func (a AuthConfig) RefreshTokenTTL() time.Duration {
+	return time.Duration(a.RefreshTokenTTLSeconds) * time.Second
+}
 
-	if err := json.NewDecoder(file).Decode(&config); err != nil {
-		log.Fatal("Could not load the config", err)
-		panic(err)
+func (s ServerConfig) TrustedProxyList() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(s.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
 	}
+	return proxies
+}
+
+func (s ServerConfig) ReadHeaderTimeout() time.Duration {
+	return time.Duration(s.ReadHeaderTimeoutMs) * time.Millisecond
+}
+
+func (s ServerConfig) ReadTimeout() time.Duration {
+	return time.Duration(s.ReadTimeoutMs) * time.Millisecond
+}
+
+func (s ServerConfig) WriteTimeout() time.Duration {
+	return time.Duration(s.WriteTimeoutMs) * time.Millisecond
+}
+
+func (s ServerConfig) IdleTimeout() time.Duration {
+	return time.Duration(s.IdleTimeoutMs) * time.Millisecond
+}
+
+func (s ServerConfig) ShutdownTimeout() time.Duration {
+	return time.Duration(s.ShutdownTimeoutMs) * time.Millisecond
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,9 +37,163 @@ func TestLoadConfig(t *testing.T) {
 	}()
 
 	conf := configs.NewConfig()
-	configs.LoadConfig(conf, tempFile)
+	err = configs.LoadConfig(conf, tempFile)
+	assert.NoError(t, err)
 
 	assert.Equal(t, "9000", conf.GetPort())
 	assert.Equal(t, "dummy-key", conf.GetApiKey())
 	assert.Equal(t, "http://mock-api/movies", conf.SearchMoviesUrl())
 }
+
+func TestLoadConfigHttpClient(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "config_test.json")
+	configJSON := `{
+		"port": "9000",
+		"http_client": {"read_timeout_ms": 1500, "max_retries": 0}
+	}`
+
+	err := os.WriteFile(tempFile, []byte(configJSON), 0644)
+	assert.NoError(t, err)
+
+	conf := configs.NewConfig()
+	err = configs.LoadConfig(conf, tempFile)
+	assert.NoError(t, err)
+
+	httpConf := conf.GetHttpClientConfig()
+	assert.Equal(t, 1500*time.Millisecond, httpConf.ReadTimeout())
+	assert.Equal(t, 0, httpConf.MaxRetries)
+	// values missing from the file keep their defaults
+	assert.Equal(t, 2*time.Second, httpConf.ConnectTimeout())
+	assert.Equal(t, 100*time.Millisecond, httpConf.RetryBaseDelay())
+}
+
+func TestLoadConfigYaml(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "config.yaml")
+	configYAML := `
+port: "9000"
+cache:
+  store: postgres
+  max_entries: 50
+`
+	err := os.WriteFile(tempFile, []byte(configYAML), 0644)
+	assert.NoError(t, err)
+
+	conf := configs.NewConfig()
+	err = configs.LoadConfig(conf, tempFile)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "9000", conf.GetPort())
+	assert.Equal(t, "postgres", conf.GetCacheConfig().Store)
+	assert.Equal(t, 50, conf.GetCacheConfig().MaxEntries)
+	assert.Equal(t, 600, conf.GetCacheConfig().SearchTTLSeconds)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	t.Run("should return an error for a missing file", func(t *testing.T) {
+		err := configs.LoadConfig(configs.NewConfig(), filepath.Join(t.TempDir(), "missing.json"))
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should return an error for an invalid file", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(tempFile, []byte(`{"port": 9000`), 0644))
+
+		err := configs.LoadConfig(configs.NewConfig(), tempFile)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	err := os.WriteFile(configFile, []byte(`{
+		"port": "9000",
+		"api_key": "file-key",
+		"get_movie_list_url": "http://file-api/movies",
+		"http_client": {"max_retries": 5}
+	}`), 0644)
+	assert.NoError(t, err)
+
+	t.Run("should layer defaults, file, environment and flags", func(t *testing.T) {
+		t.Setenv("MOVIE_API_PORT", "9100")
+		t.Setenv("MOVIE_API_API_KEY", "env-key")
+		t.Setenv("MOVIE_API_HTTP_CLIENT_MAX_RETRIES", "3")
+
+		conf, err := configs.Load(configFile, []string{"-port", "9200", "-providers.fallback=tmdb"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9200", conf.GetPort())
+		assert.Equal(t, "env-key", conf.GetApiKey())
+		assert.Equal(t, "http://file-api/movies", conf.SearchMoviesUrl())
+		assert.Equal(t, 3, conf.GetHttpClientConfig().MaxRetries)
+		assert.Equal(t, "tmdb", conf.GetProvidersConfig().Fallback)
+		assert.Equal(t, "omdb", conf.GetProvidersConfig().Primary)
+	})
+
+	t.Run("should read secrets from a mounted file", func(t *testing.T) {
+		secretFile := filepath.Join(dir, "tmdb_api_key")
+		assert.NoError(t, os.WriteFile(secretFile, []byte("secret-key\n"), 0600))
+		t.Setenv("MOVIE_API_PROVIDERS_TMDB_API_KEY_FILE", secretFile)
+
+		conf, err := configs.Load(configFile, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "secret-key", conf.GetProvidersConfig().Tmdb.ApiKey)
+	})
+
+	t.Run("should prefer the config path from the flag over the environment", func(t *testing.T) {
+		otherFile := filepath.Join(dir, "other.yml")
+		assert.NoError(t, os.WriteFile(otherFile, []byte("port: \"9300\"\n"), 0644))
+		t.Setenv("MOVIE_API_CONFIG", configFile)
+
+		conf, err := configs.Load("", []string{"-config", otherFile})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9300", conf.GetPort())
+	})
+
+	t.Run("should skip a missing default file", func(t *testing.T) {
+		t.Setenv("MOVIE_API_API_KEY", "env-key")
+
+		conf, err := configs.Load(filepath.Join(dir, "missing.json"), nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "env-key", conf.GetApiKey())
+		assert.Equal(t, configs.NewConfig().GetCacheConfig(), conf.GetCacheConfig())
+	})
+
+	t.Run("should fail for a missing file that was asked for", func(t *testing.T) {
+		t.Setenv("MOVIE_API_CONFIG", filepath.Join(dir, "missing.json"))
+
+		_, err := configs.Load(configFile, nil)
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should fail for values of the wrong type", func(t *testing.T) {
+		t.Setenv("MOVIE_API_CACHE_MAX_ENTRIES", "lots")
+
+		_, err := configs.Load(configFile, nil)
+
+		assert.EqualError(t, err, `MOVIE_API_CACHE_MAX_ENTRIES: invalid number "lots"`)
+	})
+
+	t.Run("should fail for unknown flags", func(t *testing.T) {
+		_, err := configs.Load(configFile, []string{"-no-such-setting", "1"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDatabaseDSN(t *testing.T) {
+	conf := configs.NewConfig().GetDatabaseConfig()
+
+	assert.Equal(t, "host=localhost port=5432 user=postgres dbname=postgres sslmode=disable", conf.DSN())
+
+	conf.Host = "db.staging.internal"
+	conf.Password = `it's a secret`
+	conf.SSLMode = "verify-full"
+	assert.Equal(t, `host=db.staging.internal port=5432 user=postgres password='it\'s a secret' dbname=postgres sslmode=verify-full`, conf.DSN())
+}
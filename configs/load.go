@@ -0,0 +1,208 @@
+package configs
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPrefix is prepended to every environment variable read by Load.
+	EnvPrefix = "MOVIE_API_"
+
+	configPathEnv  = EnvPrefix + "CONFIG"
+	configPathFlag = "config"
+	secretFileEnv  = "_FILE"
+)
+
+// Load builds the config in layers, each overriding the one before: the
+// defaults from NewConfig, the config file, environment variables and the
+// command line flags in args.
+//
+// The file is defaultPath unless MOVIE_API_CONFIG or -config name another
+// one; a missing default file is skipped. Every setting can be given as
+// MOVIE_API_<KEY> or -<key>, where the key is its path in the file, e.g.
+// MOVIE_API_HTTP_CLIENT_READ_TIMEOUT_MS or -http_client.read_timeout_ms.
+// MOVIE_API_<KEY>_FILE reads the value from a file, for mounted secrets.
+func Load(defaultPath string, args []string) (*config, error) {
+	config := NewConfig()
+	settings := settingsOf(config)
+
+	flags := flag.NewFlagSet("go-movie-api", flag.ContinueOnError)
+	path := flags.String(configPathFlag, "", "path to a JSON or YAML config file")
+	values := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		values[s.flagName()] = flags.String(s.flagName(), "", "overrides "+s.flagName()+" from the config file")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	explicit := true
+	switch {
+	case *path != "":
+	case os.Getenv(configPathEnv) != "":
+		*path = os.Getenv(configPathEnv)
+	default:
+		*path = defaultPath
+		explicit = false
+	}
+
+	if err := LoadConfig(config, *path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		value, found, err := s.fromEnv()
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			if err := s.set(value); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.envName(), err)
+			}
+		}
+	}
+
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		if s, ok := findSetting(settings, f.Name); ok && flagErr == nil {
+			if err := s.set(*values[f.Name]); err != nil {
+				flagErr = fmt.Errorf("-%s: %w", f.Name, err)
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	return config, nil
+}
+
+// LoadConfig reads the JSON or YAML file at path into config. Keys missing
+// from the file keep their current values. YAML files use the same keys as
+// JSON ones.
+func LoadConfig(config *config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open the config file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("could not read the config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var doc map[string]any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("could not load the config %s: %w", path, err)
+		}
+		// go through json so that both formats share the json tags
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("could not load the config %s: %w", path, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("could not load the config %s: %w", path, err)
+	}
+	return nil
+}
+
+// setting is a single string, int or bool field of config, addressed by the json
+// keys leading to it.
+type setting struct {
+	path  []string
+	field reflect.Value
+}
+
+func (s setting) flagName() string {
+	return strings.Join(s.path, ".")
+}
+
+func (s setting) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.Join(s.path, "_"))
+}
+
+func (s setting) fromEnv() (string, bool, error) {
+	if value, ok := os.LookupEnv(s.envName()); ok {
+		return value, true, nil
+	}
+
+	secretPath, ok := os.LookupEnv(s.envName() + secretFileEnv)
+	if !ok {
+		return "", false, nil
+	}
+	secret, err := os.ReadFile(secretPath)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", s.envName()+secretFileEnv, err)
+	}
+	return strings.TrimSpace(string(secret)), true, nil
+}
+
+func (s setting) set(value string) error {
+	switch s.field.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		s.field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		s.field.SetBool(b)
+	default:
+		s.field.SetString(value)
+	}
+	return nil
+}
+
+func findSetting(settings []setting, flagName string) (setting, bool) {
+	for _, s := range settings {
+		if s.flagName() == flagName {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func settingsOf(config *config) []setting {
+	return collectSettings(reflect.ValueOf(config).Elem(), nil)
+}
+
+func collectSettings(value reflect.Value, path []string) []setting {
+	var settings []setting
+	for i := 0; i < value.NumField(); i++ {
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), name)
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			settings = append(settings, collectSettings(field, fieldPath)...)
+		case reflect.String, reflect.Int, reflect.Bool:
+			settings = append(settings, setting{path: fieldPath, field: field})
+		}
+	}
+	return settings
+}
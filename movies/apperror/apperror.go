@@ -0,0 +1,109 @@
+// Package apperror holds the typed errors services and repositories return,
+// so the HTTP layer can answer each with the right status and a stable code
+// without knowing where it came from.
+package apperror
+
+import (
+	"errors"
+	"go-movie-api/movies/model"
+)
+
+// Kind is the class of an error; it decides the HTTP status.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindRateLimited
+	KindUnavailable
+	KindBadGateway
+)
+
+// InternalCode is the code of every error that isn't an *Error.
+const InternalCode = "internal_error"
+
+// Error is an error that is safe to show to clients. Code is stable and
+// meant for programs, Detail is for people. Fields lists the fields of an
+// invalid request. Err is the cause and only ends up in the logs.
+type Error struct {
+	Kind   Kind
+	Code   string
+	Detail string
+	Fields []model.FieldError
+	Err    error
+}
+
+func New(kind Kind, code, detail string) *Error {
+	return &Error{Kind: kind, Code: code, Detail: detail}
+}
+
+func Validation(code, detail string) *Error {
+	return New(KindValidation, code, detail)
+}
+
+func Unauthorized(code, detail string) *Error {
+	return New(KindUnauthorized, code, detail)
+}
+
+func Forbidden(code, detail string) *Error {
+	return New(KindForbidden, code, detail)
+}
+
+func NotFound(code, detail string) *Error {
+	return New(KindNotFound, code, detail)
+}
+
+func Conflict(code, detail string) *Error {
+	return New(KindConflict, code, detail)
+}
+
+func RateLimited(code, detail string) *Error {
+	return New(KindRateLimited, code, detail)
+}
+
+func Unavailable(code, detail string) *Error {
+	return New(KindUnavailable, code, detail)
+}
+
+func BadGateway(code, detail string) *Error {
+	return New(KindBadGateway, code, detail)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Detail + ": " + e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and code, so a sentinel still matches
+// once Wrap has given it a cause.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// From finds the *Error in err's chain. Anything else is an internal error,
+// whose message is not shown to clients.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return &Error{Kind: KindInternal, Code: InternalCode, Detail: "something went wrong, please try again later", Err: err}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"go-movie-api/movies/apperror"
+)
+
+// Scopes an API key can be given. Users signed in with a token have them all.
+const (
+	ScopeSearch    = "movies:search"
+	ScopeCartRead  = "cart:read"
+	ScopeCartWrite = "cart:write"
+	ScopeUserRead  = "user:read"
+	ScopeUserWrite = "user:write"
+)
+
+const (
+	apiKeyPrefix = "mvk_"
+	// displayPrefixLength is how much of a key is kept in the clear, so its
+	// owner can tell their keys apart.
+	displayPrefixLength = 12
+)
+
+var ErrInvalidAPIKey = apperror.Unauthorized("invalid_api_key", "invalid or revoked api key")
+
+// APIKey is a newly generated key. Only Hash is stored; Value is handed to
+// the caller once.
+type APIKey struct {
+	Value  string
+	Prefix string
+	Hash   string
+}
+
+func GenerateAPIKey() (APIKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return APIKey{}, err
+	}
+
+	value := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return APIKey{Value: value, Prefix: value[:displayPrefixLength], Hash: HashAPIKey(value)}, nil
+}
+
+// HashAPIKey is how keys are looked up. The keys are random enough that a
+// plain SHA-256 is safe, unlike for passwords.
+func HashAPIKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"go-movie-api/movies/apperror"
+	"slices"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var (
+	ErrAuthenticationRequired = apperror.Unauthorized("authentication_required", "authentication required")
+	ErrForbidden              = apperror.Forbidden("forbidden", "you are not allowed to do this")
+)
+
+// Principal is who a request is made by. Requests made with an API key act
+// as the key's owner, with the key's scopes.
+type Principal struct {
+	UserID   string
+	Role     string
+	APIKeyID string
+	Scopes   []string
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// HasScope reports whether the principal may do what scope covers.
+func (p Principal) HasScope(scope string) bool {
+	return p.APIKeyID == "" || slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the authenticated principal of the request ctx belongs to.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok && principal.UserID != ""
+}
+
+// UserID returns the authenticated user of the request ctx belongs to.
+func UserID(ctx context.Context) (string, bool) {
+	principal, ok := FromContext(ctx)
+	return principal.UserID, ok
+}
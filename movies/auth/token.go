@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/movies/apperror"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// minSecretLength is the shortest HS256 key we accept, the size of the hash.
+const minSecretLength = 32
+
+const (
+	accessToken  = "access"
+	refreshToken = "refresh"
+)
+
+var ErrInvalidToken = apperror.Unauthorized("invalid_token", "invalid or expired token")
+
+// Claims are what the app puts in its tokens. Type keeps a refresh token
+// from being used as an access token and the other way round. Only access
+// tokens carry the role, so a role change applies from the next refresh.
+type Claims struct {
+	jwt.RegisteredClaims
+	Type string `json:"typ"`
+	Role string `json:"role,omitempty"`
+}
+
+// Token is a signed token with the ID and expiry it was issued with.
+type Token struct {
+	Value     string
+	ID        string
+	ExpiresAt time.Time
+}
+
+// TokenManager issues and checks the HS256 JWTs used for sessions.
+type TokenManager struct {
+	secret     []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewTokenManager(cfg configs.AuthConfig) (*TokenManager, error) {
+	if len(cfg.JwtSecret) < minSecretLength {
+		return nil, fmt.Errorf("auth.jwt_secret must be at least %d bytes", minSecretLength)
+	}
+	if cfg.AccessTokenTTL() <= 0 || cfg.RefreshTokenTTL() <= 0 {
+		return nil, errors.New("auth token ttls must be positive")
+	}
+
+	return &TokenManager{
+		secret:     []byte(cfg.JwtSecret),
+		issuer:     cfg.Issuer,
+		accessTTL:  cfg.AccessTokenTTL(),
+		refreshTTL: cfg.RefreshTokenTTL(),
+		now:        time.Now,
+	}, nil
+}
+
+func (tm *TokenManager) AccessTTL() time.Duration {
+	return tm.accessTTL
+}
+
+func (tm *TokenManager) IssueAccess(userId string, role string) (Token, error) {
+	return tm.issue(userId, accessToken, role, tm.accessTTL)
+}
+
+func (tm *TokenManager) IssueRefresh(userId string) (Token, error) {
+	return tm.issue(userId, refreshToken, "", tm.refreshTTL)
+}
+
+// ParseAccess checks an access token and returns its claims.
+func (tm *TokenManager) ParseAccess(value string) (Claims, error) {
+	return tm.parse(value, accessToken)
+}
+
+// ParseRefresh checks a refresh token and returns its claims. Whether it
+// has been revoked is up to the caller.
+func (tm *TokenManager) ParseRefresh(value string) (Claims, error) {
+	return tm.parse(value, refreshToken)
+}
+
+func (tm *TokenManager) issue(userId string, tokenType string, role string, ttl time.Duration) (Token, error) {
+	now := tm.now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userId,
+			Issuer:    tm.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Type: tokenType,
+		Role: role,
+	}
+
+	value, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(tm.secret)
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Value: value, ID: claims.ID, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
+
+func (tm *TokenManager) parse(value string, tokenType string) (Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(value, &claims, func(*jwt.Token) (any, error) {
+		return tm.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tm.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(tm.now),
+	)
+	if err != nil || claims.Type != tokenType || claims.Subject == "" || claims.ID == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"go-movie-api/configs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAuthConfig() configs.AuthConfig {
+	return configs.AuthConfig{
+		JwtSecret:              "test-secret-that-is-at-least-32-bytes",
+		Issuer:                 "go-movie-api",
+		AccessTokenTTLSeconds:  60,
+		RefreshTokenTTLSeconds: 3600,
+	}
+}
+
+func TestNewTokenManagerRejectsShortSecret(t *testing.T) {
+	cfg := testAuthConfig()
+	cfg.JwtSecret = "too-short"
+
+	_, err := NewTokenManager(cfg)
+
+	assert.EqualError(t, err, "auth.jwt_secret must be at least 32 bytes")
+}
+
+func TestTokens(t *testing.T) {
+	tokens, err := NewTokenManager(testAuthConfig())
+	assert.NoError(t, err)
+
+	t.Run("should parse an access token it issued", func(t *testing.T) {
+		access, err := tokens.IssueAccess("42", RoleUser)
+		assert.NoError(t, err)
+
+		claims, err := tokens.ParseAccess(access.Value)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "42", claims.Subject)
+		assert.Equal(t, access.ID, claims.ID)
+	})
+
+	t.Run("should not accept a refresh token as an access token", func(t *testing.T) {
+		refresh, err := tokens.IssueRefresh("42")
+		assert.NoError(t, err)
+
+		_, err = tokens.ParseAccess(refresh.Value)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+
+		_, err = tokens.ParseRefresh(refresh.Value)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject a token signed with another secret", func(t *testing.T) {
+		cfg := testAuthConfig()
+		cfg.JwtSecret = "another-secret-that-is-at-least-32-bytes"
+		other, err := NewTokenManager(cfg)
+		assert.NoError(t, err)
+		access, err := other.IssueAccess("42", RoleUser)
+		assert.NoError(t, err)
+
+		_, err = tokens.ParseAccess(access.Value)
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("should reject an expired token", func(t *testing.T) {
+		access, err := tokens.IssueAccess("42", RoleUser)
+		assert.NoError(t, err)
+
+		later := *tokens
+		later.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		_, err = later.ParseAccess(access.Value)
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("should reject garbage", func(t *testing.T) {
+		_, err := tokens.ParseAccess("not.a.token")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse", 4)
+	assert.NoError(t, err)
+
+	assert.True(t, CheckPassword(hash, "correct horse"))
+	assert.False(t, CheckPassword(hash, "wrong horse"))
+	assert.False(t, CheckPassword("", "correct horse"))
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrDailyBudgetSpent is the reason for the ErrRateLimited errors returned
+// once a provider's daily budget is used up. Nothing is wrong with the
+// provider, so these don't count against it in the circuit breaker.
+var ErrDailyBudgetSpent = errors.New("daily request budget for the movie provider is spent")
+
+// DailyBudget counts requests to a provider per UTC day and stops them at a
+// limit. Every attempt is counted, retries included, so the provider never
+// sees more than the limit from this instance.
+type DailyBudget struct {
+	limit  int
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu   sync.Mutex
+	day  time.Time
+	used int
+}
+
+// NewDailyBudget allows limit requests a day. Set it somewhat below the
+// provider's own quota, so requests made with the same key elsewhere still fit.
+func NewDailyBudget(limit int, logger *slog.Logger) *DailyBudget {
+	return &DailyBudget{limit: limit, logger: logger, now: time.Now}
+}
+
+// take spends one call of today's budget. When there is none left it
+// returns how long until the budget resets.
+func (b *DailyBudget) take(ctx context.Context) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now().UTC()
+	if today := now.Truncate(24 * time.Hour); !today.Equal(b.day) {
+		b.day = today
+		b.used = 0
+	}
+	if b.used >= b.limit {
+		return false, b.day.Add(24 * time.Hour).Sub(now)
+	}
+
+	b.used++
+	if b.used == b.limit {
+		b.logger.WarnContext(ctx, "daily movie provider budget is spent, only cached movies are served until tomorrow", "limit", b.limit)
+	}
+	return true, 0
+}
+
+// Remaining is how many calls are left today.
+func (b *DailyBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.now().UTC().Truncate(24 * time.Hour).Equal(b.day) {
+		return b.limit
+	}
+	return b.limit - b.used
+}
+
+// spend takes one call of today's budget, or fails with ErrRateLimited once
+// it is spent.
+func (b *DailyBudget) spend(ctx context.Context) error {
+	if ok, resetIn := b.take(ctx); !ok {
+		return &UpstreamError{Kind: ErrRateLimited, RetryAfter: resetIn, Err: ErrDailyBudgetSpent}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/movies/logging"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBudget(limit int) (*DailyBudget, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)}
+	budget := NewDailyBudget(limit, logging.Discard())
+	budget.now = clock.Now
+	return budget, clock
+}
+
+func TestBudgetedHttpClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should spend the budget on every attempt, retries included", func(t *testing.T) {
+		budget, _ := newTestBudget(3)
+		server, calls := statusSequence(t, `{"Title":"Inception"}`, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+		h, _ := newTestHttpClient(2)
+		h = h.WithBudget(budget)
+
+		var out detailsBody
+		assert.NoError(t, h.Get(ctx, server.URL, url.Values{}, &out))
+		assert.Equal(t, 0, budget.Remaining())
+
+		err := h.Get(ctx, server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.ErrorIs(t, err, ErrDailyBudgetSpent)
+		var upstreamErr *UpstreamError
+		assert.ErrorAs(t, err, &upstreamErr)
+		assert.Equal(t, 2*time.Hour, upstreamErr.RetryAfter)
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should stop retrying once the budget is spent", func(t *testing.T) {
+		budget, _ := newTestBudget(2)
+		server, calls := statusSequence(t, "", http.StatusServiceUnavailable)
+		h, _ := newTestHttpClient(5)
+		h = h.WithBudget(budget)
+
+		err := h.Get(ctx, server.URL, url.Values{}, &detailsBody{})
+
+		assert.ErrorIs(t, err, ErrDailyBudgetSpent)
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should reset the budget at UTC midnight", func(t *testing.T) {
+		budget, clock := newTestBudget(1)
+		server, calls := statusSequence(t, `{"Title":"Inception"}`, http.StatusOK)
+		h, _ := newTestHttpClient(0)
+		h = h.WithBudget(budget)
+
+		assert.NoError(t, h.Get(ctx, server.URL, url.Values{}, &detailsBody{}))
+		assert.ErrorIs(t, h.Get(ctx, server.URL, url.Values{}, &detailsBody{}), ErrDailyBudgetSpent)
+
+		clock.Advance(2 * time.Hour)
+		assert.Equal(t, 1, budget.Remaining())
+
+		assert.NoError(t, h.Get(ctx, server.URL, url.Values{}, &detailsBody{}))
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should leave the client it was made from unbudgeted", func(t *testing.T) {
+		budget, _ := newTestBudget(1)
+		server, _ := statusSequence(t, `{"Title":"Inception"}`, http.StatusOK)
+		h, _ := newTestHttpClient(0)
+		h.WithBudget(budget)
+
+		assert.NoError(t, h.Get(ctx, server.URL, url.Values{}, &detailsBody{}))
+		assert.Equal(t, 1, budget.Remaining())
+	})
+}
+
+func TestCircuitBreakerIgnoresSpentBudget(t *testing.T) {
+	cb, _ := newTestBreaker(1, 30*time.Second, 1)
+
+	assert.NoError(t, cb.Allow())
+	cb.Done(&UpstreamError{Kind: ErrRateLimited, Err: ErrDailyBudgetSpent})
+
+	assert.Equal(t, CircuitClosed, cb.Status().State)
+	assert.NoError(t, cb.Allow())
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"go-movie-api/configs"
+	"go-movie-api/movies/model"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheStore keeps serialized provider responses. Implementations must be
+// safe for concurrent use and must not return entries past their ttl.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+type CacheStats struct {
+	Hits         int64 `json:"hits"`
+	Misses       int64 `json:"misses"`
+	NegativeHits int64 `json:"negativeHits"`
+}
+
+const (
+	searchCacheKind  = "search"
+	detailsCacheKind = "details"
+)
+
+// cacheEntry is what gets stored: either a response body or the message of
+// a not-found answer from the provider.
+type cacheEntry struct {
+	Body     json.RawMessage `json:"body,omitempty"`
+	NotFound string          `json:"notFound,omitempty"`
+}
+
+type CachingClient struct {
+	next        Client
+	store       CacheStore
+	searchTTL   time.Duration
+	detailsTTL  time.Duration
+	negativeTTL time.Duration
+	logger      *slog.Logger
+
+	hits         atomic.Int64
+	misses       atomic.Int64
+	negativeHits atomic.Int64
+}
+
+// NewCachingClient wraps next so that responses are served from store while
+// they are fresh. Not-found answers are cached for the negative ttl.
+func NewCachingClient(next Client, store CacheStore, cfg configs.CacheConfig, logger *slog.Logger) *CachingClient {
+	return &CachingClient{
+		next:        next,
+		store:       store,
+		searchTTL:   cfg.SearchTTL(),
+		detailsTTL:  cfg.DetailsTTL(),
+		negativeTTL: cfg.NegativeTTL(),
+		logger:      logger,
+	}
+}
+
+func (cc *CachingClient) Stats() CacheStats {
+	return CacheStats{
+		Hits:         cc.hits.Load(),
+		Misses:       cc.misses.Load(),
+		NegativeHits: cc.negativeHits.Load(),
+	}
+}
+
+func (cc *CachingClient) SearchMovies(ctx context.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
+	key := searchKey(request)
+	return cached(ctx, cc, key, cc.searchTTL, func() (model.SearchMovieResponse, error) {
+		return cc.next.SearchMovies(ctx, request)
+	})
+}
+
+func (cc *CachingClient) GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
+	key := detailsKey(request)
+	return cached(ctx, cc, key, cc.detailsTTL, func() (model.GetMovieDetailsResponse, error) {
+		return cc.next.GetMovieDetails(ctx, request)
+	})
+}
+
+func (cc *CachingClient) GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
+	key := detailsByIdKey(request)
+	return cached(ctx, cc, key, cc.detailsTTL, func() (model.GetMovieDetailsResponse, error) {
+		return cc.next.GetMovieDetailsById(ctx, request)
+	})
+}
+
+// cached returns the stored response for key, or calls load and stores its
+// result. Store failures are logged and otherwise ignored.
+func cached[T any](ctx context.Context, cc *CachingClient, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	var resp T
+	span := trace.SpanFromContext(ctx)
+
+	if value, found, err := cc.store.Get(ctx, key); err != nil {
+		cc.logger.WarnContext(ctx, "movie cache read failed", "error", err)
+	} else if found {
+		var entry cacheEntry
+		if err := json.Unmarshal(value, &entry); err == nil {
+			if entry.NotFound != "" {
+				cc.negativeHits.Add(1)
+				span.SetAttributes(attribute.Bool("movie.cache_hit", true))
+				return resp, &UpstreamError{Kind: ErrNotFound, Err: errors.New(entry.NotFound)}
+			}
+			if err := json.Unmarshal(entry.Body, &resp); err == nil {
+				cc.hits.Add(1)
+				span.SetAttributes(attribute.Bool("movie.cache_hit", true))
+				return resp, nil
+			}
+		}
+		cc.logger.WarnContext(ctx, "discarding unreadable movie cache entry", "key", key)
+	}
+
+	cc.misses.Add(1)
+	span.SetAttributes(attribute.Bool("movie.cache_hit", false))
+
+	resp, err := load()
+
+	var entry cacheEntry
+	switch {
+	case err == nil:
+		body, marshalErr := json.Marshal(resp)
+		if marshalErr != nil {
+			return resp, nil
+		}
+		entry.Body = body
+	case errors.Is(err, ErrNotFound):
+		entry.NotFound = notFoundMessage(err)
+		ttl = cc.negativeTTL
+	default:
+		return resp, err
+	}
+
+	if ttl <= 0 {
+		return resp, err
+	}
+
+	value, _ := json.Marshal(entry)
+	if setErr := cc.store.Set(ctx, key, value, ttl); setErr != nil {
+		cc.logger.WarnContext(ctx, "movie cache write failed", "error", setErr)
+	}
+
+	return resp, err
+}
+
+// defaultPage drops page 1, which is what the provider returns without a page.
+func defaultPage(page string) string {
+	if strings.TrimSpace(page) == "1" {
+		return ""
+	}
+	return page
+}
+
+func notFoundMessage(err error) string {
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) && upstreamErr.Err != nil {
+		return upstreamErr.Err.Error()
+	}
+	return err.Error()
+}
+
+func searchKey(request model.SearchMovieRequest) string {
+	return cacheKey(searchCacheKind, map[string]string{
+		"s":    request.SearchQuery,
+		"t":    request.Title,
+		"y":    request.Year,
+		"type": request.Type,
+		"page": defaultPage(request.Page),
+	})
+}
+
+func detailsKey(request model.GetMovieDetailsRequest) string {
+	return cacheKey(detailsCacheKind, map[string]string{
+		"i":    request.MovieID,
+		"t":    request.Title,
+		"y":    request.Year,
+		"type": request.Type,
+	})
+}
+
+// detailsByIdKey matches detailsKey for a request with only an id, so both share entries.
+func detailsByIdKey(request model.AddMovieToCartRequest) string {
+	return cacheKey(detailsCacheKind, map[string]string{"i": request.MovieID})
+}
+
+// cacheKey builds a key that doesn't depend on case, surrounding whitespace
+// or the order of the params. Empty params are left out.
+func cacheKey(kind string, params map[string]string) string {
+	values := url.Values{}
+	for name, value := range params {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			values.Set(name, value)
+		}
+	}
+	return kind + ":" + values.Encode()
+}
+
+// memoryCacheStore is a size-bounded LRU kept in process memory.
+type memoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    *list.List
+	index      map[string]*list.Element
+	now        func() time.Time
+}
+
+type memoryCacheItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewMemoryCacheStore(maxEntries int) *memoryCacheStore {
+	return &memoryCacheStore{
+		maxEntries: max(maxEntries, 1),
+		entries:    list.New(),
+		index:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (m *memoryCacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	item := element.Value.(*memoryCacheItem)
+	if !m.now().Before(item.expiresAt) {
+		m.remove(element)
+		return nil, false, nil
+	}
+
+	m.entries.MoveToFront(element)
+	return item.value, true, nil
+}
+
+func (m *memoryCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := m.now().Add(ttl)
+	if element, ok := m.index[key]; ok {
+		item := element.Value.(*memoryCacheItem)
+		item.value = value
+		item.expiresAt = expiresAt
+		m.entries.MoveToFront(element)
+		return nil
+	}
+
+	m.index[key] = m.entries.PushFront(&memoryCacheItem{key: key, value: value, expiresAt: expiresAt})
+	for m.entries.Len() > m.maxEntries {
+		m.remove(m.entries.Back())
+	}
+
+	return nil
+}
+
+func (m *memoryCacheStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.entries.Len()
+}
+
+func (m *memoryCacheStore) remove(element *list.Element) {
+	m.entries.Remove(element)
+	delete(m.index, element.Value.(*memoryCacheItem).key)
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/mock"
+	"go-movie-api/movies/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var testCacheConfig = configs.CacheConfig{
+	MaxEntries:         10,
+	SearchTTLSeconds:   60,
+	DetailsTTLSeconds:  3600,
+	NegativeTTLSeconds: 30,
+}
+
+func newTestCache(t *testing.T) (*CachingClient, *mock.MockClient, *memoryCacheStore, *fakeClock) {
+	ctrl := gomock.NewController(t)
+	next := mock.NewMockClient(ctrl)
+
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryCacheStore(testCacheConfig.MaxEntries)
+	store.now = clock.Now
+
+	return NewCachingClient(next, store, testCacheConfig, logging.Discard()), next, store, clock
+}
+
+func TestCachingClientSearchMovies(t *testing.T) {
+	cc, next, _, clock := newTestCache(t)
+	ctx := context.Background()
+	resp := model.SearchMovieResponse{Movies: []model.Movie{{Title: "Inception", ImdbID: "tt1375666"}}, Response: "True"}
+
+	req := model.SearchMovieRequest{SearchQuery: "Inception"}
+	next.EXPECT().SearchMovies(ctx, req).Return(resp, nil).Times(1)
+
+	first, err := cc.SearchMovies(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, resp, first)
+
+	// differently cased, padded and with the default page still hits the cache
+	second, err := cc.SearchMovies(ctx, model.SearchMovieRequest{SearchQuery: "  inception ", Page: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, resp, second)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, cc.Stats())
+
+	// a different page is a different query
+	page2 := model.SearchMovieRequest{SearchQuery: "Inception", Page: "2"}
+	next.EXPECT().SearchMovies(ctx, page2).Return(resp, nil).Times(1)
+	_, err = cc.SearchMovies(ctx, page2)
+	assert.NoError(t, err)
+
+	// search results expire after the search ttl
+	clock.Advance(61 * time.Second)
+	next.EXPECT().SearchMovies(ctx, req).Return(resp, nil).Times(1)
+	_, err = cc.SearchMovies(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), cc.Stats().Misses)
+}
+
+func TestCachingClientDetailsShareEntries(t *testing.T) {
+	cc, next, _, clock := newTestCache(t)
+	ctx := context.Background()
+	resp := model.GetMovieDetailsResponse{Title: "Inception", ImdbID: "tt1375666"}
+
+	next.EXPECT().GetMovieDetailsById(ctx, model.AddMovieToCartRequest{MovieID: "tt1375666", UserID: "123"}).Return(resp, nil).Times(1)
+
+	_, err := cc.GetMovieDetailsById(ctx, model.AddMovieToCartRequest{MovieID: "tt1375666", UserID: "123"})
+	assert.NoError(t, err)
+
+	// details outlive search results
+	clock.Advance(30 * time.Minute)
+
+	details, err := cc.GetMovieDetails(ctx, model.GetMovieDetailsRequest{MovieID: "TT1375666"})
+	assert.NoError(t, err)
+	assert.Equal(t, resp, details)
+
+	details, err = cc.GetMovieDetailsById(ctx, model.AddMovieToCartRequest{MovieID: "tt1375666", UserID: "456"})
+	assert.NoError(t, err)
+	assert.Equal(t, resp, details)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, cc.Stats())
+}
+
+func TestCachingClientNegativeCaching(t *testing.T) {
+	cc, next, _, clock := newTestCache(t)
+	ctx := context.Background()
+	req := model.SearchMovieRequest{SearchQuery: "zzzzzz"}
+	notFound := &UpstreamError{Kind: ErrNotFound, Err: errors.New("Movie not found!")}
+
+	next.EXPECT().SearchMovies(ctx, req).Return(model.SearchMovieResponse{}, notFound).Times(1)
+
+	_, err := cc.SearchMovies(ctx, req)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = cc.SearchMovies(ctx, req)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "movie not found: Movie not found!")
+	assert.Equal(t, int64(1), cc.Stats().NegativeHits)
+
+	// not-found answers expire sooner than search results
+	clock.Advance(31 * time.Second)
+	next.EXPECT().SearchMovies(ctx, req).Return(model.SearchMovieResponse{Movies: []model.Movie{{Title: "Zzzzzz"}}}, nil).Times(1)
+
+	resp, err := cc.SearchMovies(ctx, req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Movies, 1)
+}
+
+func TestCachingClientDoesNotCacheFailures(t *testing.T) {
+	cc, next, store, _ := newTestCache(t)
+	ctx := context.Background()
+	req := model.GetMovieDetailsRequest{MovieID: "tt1375666"}
+
+	next.EXPECT().GetMovieDetails(ctx, req).Return(model.GetMovieDetailsResponse{}, errProviderDown).Times(2)
+
+	_, err := cc.GetMovieDetails(ctx, req)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	_, err = cc.GetMovieDetails(ctx, req)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	assert.Equal(t, 0, store.Len())
+}
+
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func TestCachingClientFallsBackWhenStoreFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mock.NewMockClient(ctrl)
+	cc := NewCachingClient(next, failingStore{}, testCacheConfig, logging.Discard())
+	ctx := context.Background()
+	req := model.SearchMovieRequest{SearchQuery: "Inception"}
+
+	next.EXPECT().SearchMovies(ctx, req).Return(model.SearchMovieResponse{Response: "True"}, nil)
+
+	resp, err := cc.SearchMovies(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "True", resp.Response)
+}
+
+func TestMemoryCacheStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCacheStore(2)
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+
+	// reading a makes b the least recently used
+	_, found, _ := store.Get(ctx, "a")
+	assert.True(t, found)
+
+	assert.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute))
+	assert.Equal(t, 2, store.Len())
+
+	_, found, _ = store.Get(ctx, "b")
+	assert.False(t, found)
+	value, found, _ := store.Get(ctx, "a")
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), value)
+}
+
+func TestMemoryCacheStoreExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryCacheStore(10)
+	store.now = clock.Now
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+
+	clock.Advance(59 * time.Second)
+	_, found, _ := store.Get(ctx, "a")
+	assert.True(t, found)
+
+	clock.Advance(time.Second)
+	_, found, _ = store.Get(ctx, "a")
+	assert.False(t, found)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestCacheKeyIgnoresOrderCaseAndEmptyParams(t *testing.T) {
+	a := cacheKey(searchCacheKind, map[string]string{"s": "Batman ", "y": "2005", "type": ""})
+	b := cacheKey(searchCacheKind, map[string]string{"y": "2005", "s": "batman"})
+
+	assert.Equal(t, a, b)
+	assert.Equal(t, "search:s=batman&y=2005", a)
+	assert.NotEqual(t, a, cacheKey(detailsCacheKind, map[string]string{"s": "batman", "y": "2005"}))
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/configs"
+	"go-movie-api/movies/model"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerStatus is a point-in-time view of the circuit breaker.
+type BreakerStatus struct {
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	OpenedAt            *time.Time   `json:"openedAt,omitempty"`
+	RetryAfterSeconds   int          `json:"retryAfterSeconds,omitempty"`
+}
+
+// CircuitBreaker stops calls to the movie provider after FailureThreshold
+// consecutive failures. Once CoolDown has passed it lets HalfOpenMaxRequests
+// probe calls through; if they all succeed the circuit closes again, and any
+// failure re-opens it.
+type CircuitBreaker struct {
+	mu                  sync.Mutex
+	state               CircuitState
+	failures            int
+	openedAt            time.Time
+	halfOpenInFlight    int
+	halfOpenSuccesses   int
+	failureThreshold    int
+	coolDown            time.Duration
+	halfOpenMaxRequests int
+	now                 func() time.Time
+	logger              *slog.Logger
+}
+
+func NewCircuitBreaker(cfg configs.BreakerConfig, logger *slog.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		state:               CircuitClosed,
+		failureThreshold:    max(cfg.FailureThreshold, 1),
+		coolDown:            cfg.CoolDown(),
+		halfOpenMaxRequests: max(cfg.HalfOpenMaxRequests, 1),
+		now:                 time.Now,
+		logger:              logger,
+	}
+}
+
+// Allow reports whether a call may go ahead. Every allowed call must be
+// followed by exactly one call to Done with the call's outcome.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen {
+		wait := cb.openedAt.Add(cb.coolDown).Sub(cb.now())
+		if wait > 0 {
+			return &UpstreamError{Kind: ErrUpstreamUnavailable, RetryAfter: wait, Err: ErrCircuitOpen}
+		}
+		cb.setState(CircuitHalfOpen)
+	}
+
+	if cb.state == CircuitHalfOpen {
+		if cb.halfOpenInFlight >= cb.halfOpenMaxRequests {
+			return &UpstreamError{Kind: ErrUpstreamUnavailable, RetryAfter: time.Second, Err: ErrCircuitOpen}
+		}
+		cb.halfOpenInFlight++
+	}
+
+	return nil
+}
+
+// Done records the outcome of a call that was let through by Allow.
+func (cb *CircuitBreaker) Done(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if isCallerCancellation(err) || errors.Is(err, ErrDailyBudgetSpent) {
+		// says nothing about the provider, just give the probe slot back
+		if cb.state == CircuitHalfOpen && cb.halfOpenInFlight > 0 {
+			cb.halfOpenInFlight--
+		}
+		return
+	}
+
+	failed := isProviderFailure(err)
+
+	switch cb.state {
+	case CircuitHalfOpen:
+		// a call let through before the circuit opened may finish now, it never held a probe slot
+		if cb.halfOpenInFlight > 0 {
+			cb.halfOpenInFlight--
+		}
+		if failed {
+			cb.trip()
+			return
+		}
+		cb.halfOpenSuccesses++
+		if cb.halfOpenSuccesses >= cb.halfOpenMaxRequests {
+			cb.setState(CircuitClosed)
+		}
+	case CircuitClosed:
+		if !failed {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.failureThreshold {
+			cb.trip()
+		}
+	}
+}
+
+func (cb *CircuitBreaker) Status() BreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := BreakerStatus{State: cb.state, ConsecutiveFailures: cb.failures}
+	if cb.state != CircuitClosed {
+		openedAt := cb.openedAt
+		status.OpenedAt = &openedAt
+	}
+	if cb.state == CircuitOpen {
+		if wait := cb.openedAt.Add(cb.coolDown).Sub(cb.now()); wait > 0 {
+			status.RetryAfterSeconds = int((wait + time.Second - 1) / time.Second)
+		}
+	}
+	return status
+}
+
+func (cb *CircuitBreaker) trip() {
+	cb.openedAt = cb.now()
+	cb.setState(CircuitOpen)
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state != state {
+		cb.logger.Warn("movie provider circuit breaker changed state", "from", cb.state, "to", state)
+	}
+
+	cb.state = state
+	cb.halfOpenInFlight = 0
+	cb.halfOpenSuccesses = 0
+	if state == CircuitClosed {
+		cb.failures = 0
+	}
+}
+
+// isProviderFailure reports whether err says the provider is unhealthy.
+// A not-found answer is a healthy response.
+func isProviderFailure(err error) bool {
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return false
+	}
+
+	var upstreamErr *UpstreamError
+	return errors.As(err, &upstreamErr)
+}
+
+// isCallerCancellation reports whether the call ended because our own
+// context was cancelled or timed out, rather than because of the provider.
+func isCallerCancellation(err error) bool {
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return false
+	}
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+type breakerClient struct {
+	next    Client
+	breaker *CircuitBreaker
+}
+
+// NewCircuitBreakerClient wraps next so that its calls fail fast while breaker is open.
+func NewCircuitBreakerClient(next Client, breaker *CircuitBreaker) Client {
+	return breakerClient{next: next, breaker: breaker}
+}
+
+func (bc breakerClient) SearchMovies(ctx context.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
+	if err := bc.breaker.Allow(); err != nil {
+		return model.SearchMovieResponse{}, err
+	}
+
+	resp, err := bc.next.SearchMovies(ctx, request)
+	bc.breaker.Done(err)
+	return resp, err
+}
+
+func (bc breakerClient) GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
+	if err := bc.breaker.Allow(); err != nil {
+		return model.GetMovieDetailsResponse{}, err
+	}
+
+	resp, err := bc.next.GetMovieDetails(ctx, request)
+	bc.breaker.Done(err)
+	return resp, err
+}
+
+func (bc breakerClient) GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
+	if err := bc.breaker.Allow(); err != nil {
+		return model.GetMovieDetailsResponse{}, err
+	}
+
+	resp, err := bc.next.GetMovieDetailsById(ctx, request)
+	bc.breaker.Done(err)
+	return resp, err
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/mock"
+	"go-movie-api/movies/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(threshold int, coolDown time.Duration, halfOpenMax int) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker(configs.BreakerConfig{
+		FailureThreshold:    threshold,
+		CoolDownMs:          int(coolDown / time.Millisecond),
+		HalfOpenMaxRequests: halfOpenMax,
+	}, logging.Discard())
+	cb.now = clock.Now
+	return cb, clock
+}
+
+var errProviderDown = &UpstreamError{Kind: ErrUpstreamUnavailable, StatusCode: 503}
+
+func failTimes(cb *CircuitBreaker, n int) {
+	for i := 0; i < n; i++ {
+		if cb.Allow() == nil {
+			cb.Done(errProviderDown)
+		}
+	}
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	cb, _ := newTestBreaker(3, 30*time.Second, 1)
+
+	failTimes(cb, 2)
+	assert.Equal(t, CircuitClosed, cb.Status().State)
+	assert.Equal(t, 2, cb.Status().ConsecutiveFailures)
+
+	failTimes(cb, 1)
+	assert.Equal(t, CircuitOpen, cb.Status().State)
+
+	err := cb.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	cb, _ := newTestBreaker(3, 30*time.Second, 1)
+
+	failTimes(cb, 2)
+	assert.NoError(t, cb.Allow())
+	cb.Done(nil)
+	failTimes(cb, 2)
+
+	assert.Equal(t, CircuitClosed, cb.Status().State)
+}
+
+func TestCircuitBreakerIgnoresNotFoundAndCancellation(t *testing.T) {
+	cb, _ := newTestBreaker(1, 30*time.Second, 1)
+
+	assert.NoError(t, cb.Allow())
+	cb.Done(&UpstreamError{Kind: ErrNotFound, StatusCode: 404})
+	assert.NoError(t, cb.Allow())
+	cb.Done(context.Canceled)
+
+	assert.Equal(t, CircuitClosed, cb.Status().State)
+}
+
+func TestCircuitBreakerReportsRetryAfterWhileOpen(t *testing.T) {
+	cb, clock := newTestBreaker(1, 30*time.Second, 1)
+	failTimes(cb, 1)
+
+	clock.Advance(10 * time.Second)
+
+	var upstreamErr *UpstreamError
+	assert.ErrorAs(t, cb.Allow(), &upstreamErr)
+	assert.Equal(t, 20*time.Second, upstreamErr.RetryAfter)
+	assert.Equal(t, 20, cb.Status().RetryAfterSeconds)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	t.Run("should close after a successful probe", func(t *testing.T) {
+		cb, clock := newTestBreaker(1, 30*time.Second, 1)
+		failTimes(cb, 1)
+
+		clock.Advance(30 * time.Second)
+
+		assert.NoError(t, cb.Allow())
+		assert.Equal(t, CircuitHalfOpen, cb.Status().State)
+
+		// only one probe at a time
+		assert.ErrorIs(t, cb.Allow(), ErrCircuitOpen)
+
+		cb.Done(nil)
+		assert.Equal(t, CircuitClosed, cb.Status().State)
+		assert.NoError(t, cb.Allow())
+	})
+
+	t.Run("should re-open when the probe fails", func(t *testing.T) {
+		cb, clock := newTestBreaker(1, 30*time.Second, 1)
+		failTimes(cb, 1)
+
+		clock.Advance(31 * time.Second)
+		assert.NoError(t, cb.Allow())
+		cb.Done(errProviderDown)
+
+		assert.Equal(t, CircuitOpen, cb.Status().State)
+		assert.ErrorIs(t, cb.Allow(), ErrCircuitOpen)
+
+		clock.Advance(29 * time.Second)
+		assert.ErrorIs(t, cb.Allow(), ErrCircuitOpen)
+
+		clock.Advance(time.Second)
+		assert.NoError(t, cb.Allow())
+	})
+
+	t.Run("should need every probe to succeed before closing", func(t *testing.T) {
+		cb, clock := newTestBreaker(1, time.Second, 2)
+		failTimes(cb, 1)
+		clock.Advance(time.Second)
+
+		assert.NoError(t, cb.Allow())
+		assert.NoError(t, cb.Allow())
+		assert.ErrorIs(t, cb.Allow(), ErrCircuitOpen)
+
+		cb.Done(nil)
+		assert.Equal(t, CircuitHalfOpen, cb.Status().State)
+		cb.Done(nil)
+		assert.Equal(t, CircuitClosed, cb.Status().State)
+	})
+
+	t.Run("should free the probe slot when the caller cancels", func(t *testing.T) {
+		cb, clock := newTestBreaker(1, time.Second, 1)
+		failTimes(cb, 1)
+		clock.Advance(time.Second)
+
+		assert.NoError(t, cb.Allow())
+		cb.Done(context.Canceled)
+
+		assert.Equal(t, CircuitHalfOpen, cb.Status().State)
+		assert.NoError(t, cb.Allow())
+	})
+}
+
+func TestCircuitBreakerClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	next := mock.NewMockClient(ctrl)
+	cb, clock := newTestBreaker(2, 30*time.Second, 1)
+	c := NewCircuitBreakerClient(next, cb)
+	ctx := context.Background()
+	req := model.SearchMovieRequest{SearchQuery: "Inception"}
+
+	next.EXPECT().SearchMovies(ctx, req).Return(model.SearchMovieResponse{}, errProviderDown).Times(2)
+
+	_, err := c.SearchMovies(ctx, req)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	_, err = c.SearchMovies(ctx, req)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+
+	// open: the wrapped client isn't called
+	_, err = c.SearchMovies(ctx, req)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = c.GetMovieDetailsById(ctx, model.AddMovieToCartRequest{MovieID: "tt1375666"})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	clock.Advance(30 * time.Second)
+	detailsReq := model.GetMovieDetailsRequest{MovieID: "tt1375666"}
+	next.EXPECT().GetMovieDetails(ctx, detailsReq).Return(model.GetMovieDetailsResponse{Title: "Inception"}, nil)
+
+	resp, err := c.GetMovieDetails(ctx, detailsReq)
+	assert.NoError(t, err)
+	assert.Equal(t, "Inception", resp.Title)
+	assert.Equal(t, CircuitClosed, cb.Status().State)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/movies/model"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type CoalescingStats struct {
+	Calls        int64 `json:"calls"`
+	Deduplicated int64 `json:"deduplicated"`
+}
+
+// CoalescingClient makes identical concurrent requests share one call to the
+// wrapped client. Requests are identical when their cache keys match.
+type CoalescingClient struct {
+	next  Client
+	group singleflight.Group
+
+	calls        atomic.Int64
+	deduplicated atomic.Int64
+}
+
+func NewCoalescingClient(next Client) *CoalescingClient {
+	return &CoalescingClient{next: next}
+}
+
+func (cc *CoalescingClient) Stats() CoalescingStats {
+	return CoalescingStats{
+		Calls:        cc.calls.Load(),
+		Deduplicated: cc.deduplicated.Load(),
+	}
+}
+
+func (cc *CoalescingClient) SearchMovies(ctx context.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
+	key := searchKey(request)
+	return coalesce(ctx, cc, key, func(ctx context.Context) (model.SearchMovieResponse, error) {
+		return cc.next.SearchMovies(ctx, request)
+	})
+}
+
+func (cc *CoalescingClient) GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
+	key := detailsKey(request)
+	return coalesce(ctx, cc, key, func(ctx context.Context) (model.GetMovieDetailsResponse, error) {
+		return cc.next.GetMovieDetails(ctx, request)
+	})
+}
+
+func (cc *CoalescingClient) GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
+	key := detailsByIdKey(request)
+	return coalesce(ctx, cc, key, func(ctx context.Context) (model.GetMovieDetailsResponse, error) {
+		return cc.next.GetMovieDetailsById(ctx, request)
+	})
+}
+
+// coalesce runs call once for all concurrent callers with the same key. The
+// shared call doesn't inherit the first caller's cancellation, so one client
+// going away doesn't fail everyone else; it's still bounded by the http
+// client timeouts. Each caller stops waiting when its own context is done.
+func coalesce[T any](ctx context.Context, cc *CoalescingClient, key string, call func(ctx context.Context) (T, error)) (T, error) {
+	leader := false
+	results := cc.group.DoChan(key, func() (any, error) {
+		leader = true
+		return call(context.WithoutCancel(ctx))
+	})
+	cc.calls.Add(1)
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case result := <-results:
+		// the call has finished, so reading leader can't race with the write above
+		if !leader {
+			cc.deduplicated.Add(1)
+		}
+		resp, _ := result.Val.(T)
+		return resp, result.Err
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/model"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingClient counts upstream calls and holds each one until release is closed.
+type blockingClient struct {
+	upstreamCalls atomic.Int32
+	release       chan struct{}
+	err           error
+}
+
+func (b *blockingClient) SearchMovies(ctx context.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
+	b.upstreamCalls.Add(1)
+	<-b.release
+	return model.SearchMovieResponse{Movies: []model.Movie{{Title: request.SearchQuery}}}, b.err
+}
+
+func (b *blockingClient) GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
+	b.upstreamCalls.Add(1)
+	<-b.release
+	return model.GetMovieDetailsResponse{ImdbID: request.MovieID}, b.err
+}
+
+func (b *blockingClient) GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
+	b.upstreamCalls.Add(1)
+	<-b.release
+	return model.GetMovieDetailsResponse{ImdbID: request.MovieID}, b.err
+}
+
+// waitForCallers blocks until n callers have joined a coalesced call.
+func waitForCallers(t *testing.T, cc *CoalescingClient, n int64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for cc.Stats().Calls < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d callers joined", cc.Stats().Calls, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalescingClientSharesOneUpstreamCall(t *testing.T) {
+	const callers = 50
+
+	upstream := &blockingClient{release: make(chan struct{})}
+	cc := NewCoalescingClient(upstream)
+
+	var wg sync.WaitGroup
+	results := make([]model.SearchMovieResponse, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// differently cased queries are the same request
+			query := "Batman"
+			if i%2 == 0 {
+				query = "batman "
+			}
+			results[i], errs[i] = cc.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: query})
+		}(i)
+	}
+
+	waitForCallers(t, cc, callers)
+	close(upstream.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), upstream.upstreamCalls.Load())
+	for i := 0; i < callers; i++ {
+		assert.NoError(t, errs[i])
+		assert.Len(t, results[i].Movies, 1)
+	}
+	assert.Equal(t, CoalescingStats{Calls: callers, Deduplicated: callers - 1}, cc.Stats())
+}
+
+func TestCoalescingClientSharesErrors(t *testing.T) {
+	upstream := &blockingClient{release: make(chan struct{}), err: errProviderDown}
+	cc := NewCoalescingClient(upstream)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = cc.GetMovieDetailsById(context.Background(), model.AddMovieToCartRequest{MovieID: "tt1375666", UserID: "123"})
+		}(i)
+	}
+
+	waitForCallers(t, cc, 3)
+	close(upstream.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), upstream.upstreamCalls.Load())
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	}
+}
+
+func TestCoalescingClientKeepsDifferentRequestsApart(t *testing.T) {
+	upstream := &blockingClient{release: make(chan struct{})}
+	close(upstream.release)
+	cc := NewCoalescingClient(upstream)
+
+	first, err := cc.GetMovieDetails(context.Background(), model.GetMovieDetailsRequest{MovieID: "tt1375666"})
+	assert.NoError(t, err)
+	second, err := cc.GetMovieDetails(context.Background(), model.GetMovieDetailsRequest{MovieID: "tt0816692"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "tt1375666", first.ImdbID)
+	assert.Equal(t, "tt0816692", second.ImdbID)
+	assert.Equal(t, int32(2), upstream.upstreamCalls.Load())
+	assert.Equal(t, int64(0), cc.Stats().Deduplicated)
+}
+
+func TestCoalescingClientCancelledCallerDoesNotFailOthers(t *testing.T) {
+	upstream := &blockingClient{release: make(chan struct{})}
+	cc := NewCoalescingClient(upstream)
+	req := model.SearchMovieRequest{SearchQuery: "Inception"}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := cc.SearchMovies(leaderCtx, req)
+		leaderErr <- err
+	}()
+	waitForCallers(t, cc, 1)
+
+	followerErr := make(chan error, 1)
+	go func() {
+		_, err := cc.SearchMovies(context.Background(), req)
+		followerErr <- err
+	}()
+	waitForCallers(t, cc, 2)
+
+	cancelLeader()
+	assert.True(t, errors.Is(<-leaderErr, context.Canceled))
+
+	close(upstream.release)
+	assert.NoError(t, <-followerErr)
+	assert.Equal(t, int32(1), upstream.upstreamCalls.Load())
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"go-movie-api/movies/apperror"
+	"time"
+)
+
+var (
+	ErrUpstreamUnavailable = apperror.Unavailable("provider_unavailable", "movie provider is unavailable")
+	ErrRateLimited         = apperror.RateLimited("provider_rate_limited", "movie provider rate limit reached")
+	ErrNotFound            = apperror.NotFound("movie_not_found", "movie not found")
+	ErrMalformedResponse   = apperror.BadGateway("provider_bad_response", "malformed response from movie provider")
+)
+
+// UpstreamError is returned for every failed call to the movie provider.
+// It unwraps to one of the sentinel errors above so callers can use errors.Is.
+type UpstreamError struct {
+	Kind       error
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+
+	// temporary marks failures worth retrying: network errors, 429s and 5xx responses
+	temporary bool
+}
+
+func (e *UpstreamError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("%s: status %d", e.Kind, e.StatusCode)
+	default:
+		return e.Kind.Error()
+	}
+}
+
+func (e *UpstreamError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// omdbError maps the error messages OMDb sends in a 200 response with
+// "Response":"False" to a typed error. Messages that aren't about
+// availability (e.g. "Too many results.") are validation errors rather than
+// UpstreamErrors, so they don't count against the provider's health.
+func omdbError(message string) error {
+	switch message {
+	case "":
+		return nil
+	case "Movie not found!", "Series not found!", "Episode not found!", "Incorrect IMDb ID.":
+		return &UpstreamError{Kind: ErrNotFound, Err: errors.New(message)}
+	case "Request limit reached!":
+		return &UpstreamError{Kind: ErrRateLimited, Err: errors.New(message)}
+	case "Invalid API key!", "No API key provided.":
+		return &UpstreamError{Kind: ErrUpstreamUnavailable, Err: errors.New(message)}
+	default:
+		return apperror.Validation("search_rejected", message)
+	}
+}
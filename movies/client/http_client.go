@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/tracing"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HttpClient makes GET requests to the movie provider. Every attempt is bounded
+// by the configured connect/read timeouts, and network errors, 429s and 5xx
+// responses are retried with exponential backoff and jitter. Each attempt is
+// a client span whose trace context is sent along in W3C headers.
+type HttpClient struct {
+	client     *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+	jitter     func(d time.Duration) time.Duration
+	logger     *slog.Logger
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	budget     *DailyBudget
+}
+
+func NewHttpClient(cfg configs.HttpClientConfig, logger *slog.Logger, tracerProvider trace.TracerProvider) *HttpClient {
+	dialer := &net.Dialer{Timeout: cfg.ConnectTimeout()}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = cfg.ConnectTimeout()
+	transport.ResponseHeaderTimeout = cfg.ReadTimeout()
+
+	return &HttpClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.ConnectTimeout() + cfg.ReadTimeout(),
+		},
+		maxRetries: cfg.MaxRetries,
+		baseDelay:  cfg.RetryBaseDelay(),
+		maxDelay:   cfg.RetryMaxDelay(),
+		sleep:      sleepContext,
+		jitter:     equalJitter,
+		logger:     logger,
+		tracer:     tracing.Tracer(tracerProvider),
+		propagator: tracing.Propagator(),
+	}
+}
+
+// WithBudget returns a client that spends budget on every attempt, retries
+// included, and fails with ErrRateLimited once it is spent. It shares the
+// connections of h.
+func (h *HttpClient) WithBudget(budget *DailyBudget) *HttpClient {
+	budgeted := *h
+	budgeted.budget = budget
+	return &budgeted
+}
+
+// Close releases idle connections held by the underlying transport.
+func (h *HttpClient) Close() {
+	h.client.CloseIdleConnections()
+}
+
+// Get sends a GET request to apiUrl with queryParams and decodes the JSON
+// body into out. Failures are returned as *UpstreamError unless the context
+// was cancelled, in which case the context error is returned.
+func (h *HttpClient) Get(ctx context.Context, apiUrl string, queryParams url.Values, out any) error {
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = queryParams.Encode()
+
+	for attempt := 0; ; attempt++ {
+		if h.budget != nil {
+			if err := h.budget.spend(ctx); err != nil {
+				return err
+			}
+		}
+
+		start := time.Now()
+		err := h.attempt(ctx, u, attempt, out)
+		h.logger.DebugContext(ctx, "movie provider request", "url", logging.RedactURL(u), "attempt", attempt+1,
+			"duration_ms", time.Since(start).Milliseconds(), "error", err)
+		if err == nil {
+			return nil
+		}
+
+		var upstreamErr *UpstreamError
+		if !errors.As(err, &upstreamErr) || !upstreamErr.temporary || attempt >= h.maxRetries {
+			return err
+		}
+
+		delay := h.backoff(attempt)
+		if upstreamErr.RetryAfter > 0 {
+			if upstreamErr.RetryAfter > h.maxDelay {
+				// the provider wants us to wait longer than we're willing to hold the request
+				return err
+			}
+			delay = upstreamErr.RetryAfter
+		}
+
+		h.logger.WarnContext(ctx, "movie provider request failed, retrying", "attempt", attempt+1, "delay", delay, "error", err)
+
+		if err := h.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *HttpClient) attempt(ctx context.Context, u *url.URL, attempt int, out any) error {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodGet,
+		semconv.URLFull(logging.RedactURL(u)),
+		semconv.ServerAddress(u.Hostname()),
+	}
+	if attempt > 0 {
+		attrs = append(attrs, semconv.HTTPRequestResendCount(attempt))
+	}
+
+	ctx, span := h.tracer.Start(ctx, http.MethodGet, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	err := h.do(ctx, u, out)
+	tracing.End(span, err)
+	return err
+}
+
+func (h *HttpClient) do(ctx context.Context, u *url.URL, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	h.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// transport errors quote the url, api key included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = logging.RedactURL(u)
+		}
+		return &UpstreamError{Kind: ErrUpstreamUnavailable, Err: err, temporary: true}
+	}
+	defer resp.Body.Close()
+
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// drain what's left so the connection can be reused
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return statusError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &UpstreamError{Kind: ErrMalformedResponse, StatusCode: resp.StatusCode, Err: err}
+	}
+
+	return nil
+}
+
+func statusError(resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &UpstreamError{
+			Kind:       ErrRateLimited,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			temporary:  true,
+		}
+	case resp.StatusCode == http.StatusNotFound:
+		return &UpstreamError{Kind: ErrNotFound, StatusCode: resp.StatusCode}
+	case resp.StatusCode >= 500:
+		return &UpstreamError{Kind: ErrUpstreamUnavailable, StatusCode: resp.StatusCode, temporary: true}
+	default:
+		return &UpstreamError{Kind: ErrUpstreamUnavailable, StatusCode: resp.StatusCode}
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+func (h *HttpClient) backoff(attempt int) time.Duration {
+	delay := h.maxDelay
+	// keep the shift small enough that it can't overflow
+	if attempt < 32 {
+		if d := h.baseDelay << attempt; d > 0 && d < h.maxDelay {
+			delay = d
+		}
+	}
+	return h.jitter(delay)
+}
+
+// equalJitter keeps half of the delay and randomises the other half, so
+// retries from concurrent callers spread out without collapsing to zero.
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(d-half)+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type roundTripperFunc func(*http.Request) *http.Response
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+type detailsBody struct {
+	Title string
+}
+
+// newTestHttpClient returns a client that records backoff delays instead of sleeping.
+func newTestHttpClient(maxRetries int) (*HttpClient, *[]time.Duration) {
+	h := NewHttpClient(configs.HttpClientConfig{
+		ConnectTimeoutMs: 1000,
+		ReadTimeoutMs:    1000,
+		MaxRetries:       maxRetries,
+		RetryBaseDelayMs: 100,
+		RetryMaxDelayMs:  1000,
+	}, logging.Discard(), noop.NewTracerProvider())
+
+	var delays []time.Duration
+	h.jitter = func(d time.Duration) time.Duration { return d }
+	h.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return h, &delays
+}
+
+// statusSequence serves the given status codes in order, repeating the last one.
+func statusSequence(t *testing.T, body string, statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		w.WriteHeader(statuses[n])
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestHttpClientGet(t *testing.T) {
+	t.Run("should decode the body on success", func(t *testing.T) {
+		server, calls := statusSequence(t, `{"Title":"Inception"}`, http.StatusOK)
+		h, delays := newTestHttpClient(2)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{"i": {"tt1375666"}}, &out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Inception", out.Title)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("should retry 5xx responses with exponential backoff", func(t *testing.T) {
+		server, calls := statusSequence(t, `{"Title":"Inception"}`, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+		h, delays := newTestHttpClient(3)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Inception", out.Title)
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *delays)
+	})
+
+	t.Run("should return upstream unavailable once retries are exhausted", func(t *testing.T) {
+		server, calls := statusSequence(t, ``, http.StatusInternalServerError)
+		h, _ := newTestHttpClient(2)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should return rate limited and honour retry after", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		h, delays := newTestHttpClient(1)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		assert.Equal(t, []time.Duration{time.Second}, *delays)
+
+		var upstreamErr *UpstreamError
+		assert.ErrorAs(t, err, &upstreamErr)
+		assert.Equal(t, time.Second, upstreamErr.RetryAfter)
+	})
+
+	t.Run("should not retry when retry after is longer than the max delay", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		h, _ := newTestHttpClient(3)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should return not found without retrying", func(t *testing.T) {
+		server, calls := statusSequence(t, ``, http.StatusNotFound)
+		h, _ := newTestHttpClient(3)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should not retry other client errors", func(t *testing.T) {
+		server, calls := statusSequence(t, `{"Response":"False","Error":"Invalid API key!"}`, http.StatusUnauthorized)
+		h, _ := newTestHttpClient(3)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should return malformed response for invalid json", func(t *testing.T) {
+		server, calls := statusSequence(t, `<html>`, http.StatusOK)
+		h, _ := newTestHttpClient(3)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrMalformedResponse)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should retry network errors", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		h, delays := newTestHttpClient(2)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+		assert.Len(t, *delays, 2)
+	})
+
+	t.Run("should keep the api key out of connection errors", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		h, _ := newTestHttpClient(0)
+
+		var out detailsBody
+		err := h.Get(context.Background(), server.URL, url.Values{"apikey": {"secret-key"}}, &out)
+
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+		assert.NotContains(t, err.Error(), "secret-key")
+		assert.Contains(t, err.Error(), "apikey=REDACTED")
+	})
+
+	t.Run("should pass the request id on to the provider", func(t *testing.T) {
+		var requestId string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestId = r.Header.Get(logging.RequestIDHeader)
+			_, _ = w.Write([]byte(`{"Title":"Inception"}`))
+		}))
+		defer server.Close()
+		h, _ := newTestHttpClient(0)
+
+		var out detailsBody
+		err := h.Get(logging.WithRequestID(context.Background(), "req-1"), server.URL, url.Values{}, &out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "req-1", requestId)
+	})
+
+	t.Run("should stop retrying when the context is cancelled", func(t *testing.T) {
+		server, calls := statusSequence(t, ``, http.StatusServiceUnavailable)
+		h, _ := newTestHttpClient(5)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		h.sleep = func(ctx context.Context, d time.Duration) error {
+			cancel()
+			return sleepContext(ctx, d)
+		}
+
+		var out detailsBody
+		err := h.Get(ctx, server.URL, url.Values{}, &out)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+}
+
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestHttpClientClosesResponseBody(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusServiceUnavailable} {
+		body := &trackingBody{Reader: strings.NewReader(`{"Title":"Inception"}`)}
+		h, _ := newTestHttpClient(0)
+		h.client = &http.Client{
+			Transport: roundTripperFunc(func(req *http.Request) *http.Response {
+				return &http.Response{StatusCode: status, Body: body, Header: make(http.Header)}
+			}),
+		}
+
+		var out detailsBody
+		_ = h.Get(context.Background(), "http://mock-api/movies", url.Values{}, &out)
+
+		assert.True(t, body.closed, "body not closed for status %d", status)
+	}
+}
+
+func TestBackoffIsCappedAtMaxDelay(t *testing.T) {
+	h, _ := newTestHttpClient(10)
+
+	assert.Equal(t, 100*time.Millisecond, h.backoff(0))
+	assert.Equal(t, 800*time.Millisecond, h.backoff(3))
+	assert.Equal(t, time.Second, h.backoff(4))
+	assert.Equal(t, time.Second, h.backoff(70))
+}
+
+func TestEqualJitterStaysWithinBounds(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := equalJitter(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+	}
+}
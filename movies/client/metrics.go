@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/model"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ProviderMetrics counts and times calls to each movie provider.
+type ProviderMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewProviderMetrics(reg prometheus.Registerer) *ProviderMetrics {
+	m := &ProviderMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "movie_provider_requests_total",
+			Help: "Calls to movie providers by provider, operation and outcome.",
+		}, []string{"provider", "operation", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "movie_provider_request_duration_seconds",
+			Help:    "Movie provider call latency, including retries, by provider and operation.",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2, 4, 8, 16},
+		}, []string{"provider", "operation"}),
+	}
+	reg.MustRegister(m.requests, m.duration)
+	return m
+}
+
+func (m *ProviderMetrics) observe(provider string, operation string, start time.Time, err error) {
+	m.requests.WithLabelValues(provider, operation, callOutcome(err)).Inc()
+	m.duration.WithLabelValues(provider, operation).Observe(time.Since(start).Seconds())
+}
+
+func callOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case isCallerCancellation(err):
+		return "canceled"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrDailyBudgetSpent):
+		return "budget_spent"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrMalformedResponse):
+		return "malformed"
+	case errors.Is(err, ErrUpstreamUnavailable):
+		return "unavailable"
+	default:
+		return "error"
+	}
+}
+
+type instrumentedProvider struct {
+	next    Provider
+	metrics *ProviderMetrics
+}
+
+// NewInstrumentedProvider wraps next so that every call is counted and timed.
+func NewInstrumentedProvider(next Provider, metrics *ProviderMetrics) Provider {
+	return instrumentedProvider{next: next, metrics: metrics}
+}
+
+func (ip instrumentedProvider) Name() string {
+	return ip.next.Name()
+}
+
+func (ip instrumentedProvider) Endpoint() string {
+	return ip.next.Endpoint()
+}
+
+func (ip instrumentedProvider) Search(ctx context.Context, query SearchQuery) (model.MovieSearchResult, error) {
+	start := time.Now()
+	result, err := ip.next.Search(ctx, query)
+	ip.metrics.observe(ip.next.Name(), "search", start, err)
+	return result, err
+}
+
+func (ip instrumentedProvider) Lookup(ctx context.Context, query LookupQuery) (model.MovieMetadata, error) {
+	start := time.Now()
+	movie, err := ip.next.Lookup(ctx, query)
+	ip.metrics.observe(ip.next.Name(), "lookup", start, err)
+	return movie, err
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/movies/model"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentedProvider(t *testing.T) {
+	server := newStandIn(t, func(r *http.Request) (int, string) {
+		switch r.URL.Query().Get("i") {
+		case "tt1375666":
+			return 200, `{"Title":"Inception","imdbID":"tt1375666","Response":"True"}`
+		case "tt0000000":
+			return 200, `{"Response":"False","Error":"Incorrect IMDb ID."}`
+		default:
+			return 500, `oops`
+		}
+	})
+	metrics := NewProviderMetrics(prometheus.NewRegistry())
+	c := newOmdbClient(t, server.URL).Instrument(metrics)
+
+	_, err := c.GetMovieDetailsById(context.Background(), model.AddMovieToCartRequest{MovieID: "tt1375666"})
+	assert.NoError(t, err)
+	_, err = c.GetMovieDetailsById(context.Background(), model.AddMovieToCartRequest{MovieID: "tt0000000"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("omdb", "lookup", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("omdb", "lookup", "not_found")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.duration))
+}
+
+func TestCallOutcome(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, "ok", callOutcome(nil))
+	assert.Equal(t, "canceled", callOutcome(ctx.Err()))
+	assert.Equal(t, "rate_limited", callOutcome(ErrRateLimited))
+	assert.Equal(t, "malformed", callOutcome(ErrMalformedResponse))
+	assert.Equal(t, "unavailable", callOutcome(ErrUpstreamUnavailable))
+}
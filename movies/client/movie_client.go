@@ -1,154 +1,228 @@
 package client
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"go-movie-api/configs"
 	"go-movie-api/movies/model"
-	"log"
-	"net/http"
+	"log/slog"
+	"net"
 	"net/url"
+	"strings"
 
-	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client interface {
-	SearchMovies(ctx *gin.Context, request model.SearchMovieRequest) (movie model.SearchMovieResponse, err error)
-	GetMovieDetails(ctx *gin.Context, request model.GetMovieDetailsRequest) (movie model.GetMovieDetailsResponse, err error)
-	GetMovieDetailsById(ctx *gin.Context, request model.AddMovieToCartRequest) (movie model.GetMovieDetailsResponse, err error)
+	SearchMovies(ctx context.Context, request model.SearchMovieRequest) (movie model.SearchMovieResponse, err error)
+	GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (movie model.GetMovieDetailsResponse, err error)
+	GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (movie model.GetMovieDetailsResponse, err error)
 }
 
+// client serves requests from the first provider, trying the next one only
+// when a provider is failing. Not-found answers are returned as they are.
 type client struct {
-	appConfig configs.Config
+	providers  []Provider
+	httpClient *HttpClient
+	logger     *slog.Logger
 }
 
-func NewClient(appConfig configs.Config) client {
-	return client{appConfig: appConfig}
-}
-
-func (c client) SearchMovies(ctx *gin.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
-	log.Println("initiating movies search req", request)
-
-	queryParams := constructParamsForSearchMovies(request, c.appConfig)
+// NewClient builds the primary and fallback providers named in the config.
+func NewClient(appConfig configs.Config, logger *slog.Logger, tracerProvider trace.TracerProvider) (client, error) {
+	httpClient := NewHttpClient(appConfig.GetHttpClientConfig(), logger, tracerProvider)
+	providersConfig := appConfig.GetProvidersConfig()
 
-	var searchMovieResponse model.SearchMovieResponse
-	if err := makeGetRequest(c.appConfig.SearchMoviesUrl(), queryParams, &searchMovieResponse); err != nil {
-		log.Println(err)
-		return model.SearchMovieResponse{}, err
+	names := []string{providersConfig.Primary}
+	if providersConfig.Fallback != "" && !strings.EqualFold(providersConfig.Fallback, providersConfig.Primary) {
+		names = append(names, providersConfig.Fallback)
 	}
 
-	log.Println("fetched response from movies search")
+	c := client{httpClient: httpClient, logger: logger}
+	for _, name := range names {
+		provider, err := newProvider(name, appConfig, httpClient, logger)
+		if err != nil {
+			return client{}, err
+		}
+		c.providers = append(c.providers, provider)
+	}
 
-	return searchMovieResponse, nil
+	return c, nil
 }
 
-func (c client) GetMovieDetails(ctx *gin.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
-	log.Println("initiating movie search req", request)
-
-	queryParams := constructParamsForGetMovieDetails(request, c.appConfig)
-
-	var movieDetailsResponse model.GetMovieDetailsResponse
-	if err := makeGetRequest(c.appConfig.SearchMoviesUrl(), queryParams, &movieDetailsResponse); err != nil {
-		log.Println(err)
-		return model.GetMovieDetailsResponse{}, err
+func newProvider(name string, appConfig configs.Config, httpClient *HttpClient, logger *slog.Logger) (Provider, error) {
+	switch strings.ToLower(name) {
+	case "", "omdb":
+		if budget := appConfig.GetProvidersConfig().OmdbDailyBudget; budget > 0 {
+			httpClient = httpClient.WithBudget(NewDailyBudget(budget, logger))
+		}
+		return NewOmdbProvider(appConfig.GetApiKey(), appConfig.SearchMoviesUrl(), httpClient), nil
+	case "tmdb":
+		return NewTmdbProvider(appConfig.GetProvidersConfig().Tmdb, httpClient), nil
+	default:
+		return nil, fmt.Errorf("unknown movie provider %q", name)
 	}
-
-	log.Println("fetched the movie details successfully")
-
-	return movieDetailsResponse, nil
 }
 
-func (c client) GetMovieDetailsById(ctx *gin.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
-	log.Println("initiating movie search req", request)
-
-	queryParams := constructParamsForGetMovieDetailsById(request, c.appConfig)
-	var movieDetailsResponse model.GetMovieDetailsResponse
-	if err := makeGetRequest(c.appConfig.SearchMoviesUrl(), queryParams, &movieDetailsResponse); err != nil {
-		log.Println(err)
-		return model.GetMovieDetailsResponse{}, err
+// Instrument returns a copy of the client whose provider calls are recorded in metrics.
+func (c client) Instrument(metrics *ProviderMetrics) client {
+	providers := make([]Provider, 0, len(c.providers))
+	for _, provider := range c.providers {
+		providers = append(providers, NewInstrumentedProvider(provider, metrics))
 	}
-
-	log.Println("fetched movie details for id", request.MovieID)
-
-	return movieDetailsResponse, nil
+	c.providers = providers
+	return c
 }
 
-func constructParamsForSearchMovies(request model.SearchMovieRequest, appConfig configs.Config) url.Values {
-	params := url.Values{}
-	params.Add("apikey", appConfig.GetApiKey())
-	params.Add("s", request.SearchQuery)
+// Close releases the connections held by the client.
+func (c client) Close() {
+	c.httpClient.Close()
+}
 
-	log.Println("request", request)
+// Reachability opens a connection to each provider, which tells whether it
+// can be reached without spending any of its request quota. Providers that
+// can be reached map to nil.
+func (c client) Reachability(ctx context.Context) map[string]error {
+	results := make(map[string]error, len(c.providers))
+	for _, provider := range c.providers {
+		results[provider.Name()] = dial(ctx, provider.Endpoint())
+	}
+	return results
+}
 
-	if request.Title != "" {
-		params.Add("t", request.Title)
+func dial(ctx context.Context, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
 	}
-	if request.Year != "" {
-		params.Add("y", request.Year)
+	if u.Host == "" {
+		return fmt.Errorf("no host in %q", endpoint)
 	}
-	if request.Type != "" {
-		params.Add("type", request.Type)
+
+	address := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
 	}
-	if request.Page != "" {
-		params.Add("page", request.Page)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
 	}
-	return params
+	return conn.Close()
 }
 
-func constructParamsForGetMovieDetails(request model.GetMovieDetailsRequest, appConfig configs.Config) url.Values {
-	params := url.Values{}
-	params.Add("apikey", appConfig.GetApiKey())
+func (c client) SearchMovies(ctx context.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
+	c.logger.DebugContext(ctx, "searching movies", "query", request.SearchQuery, "title", request.Title, "year", request.Year, "type", request.Type, "page", request.Page)
 
-	if request.MovieID != "" {
-		params.Add("i", request.MovieID)
-	}
-	if request.Title != "" {
-		params.Add("t", request.Title)
-	}
-	if request.Year != "" {
-		params.Add("y", request.Year)
+	query := SearchQuery{
+		Text:  request.SearchQuery,
+		Title: request.Title,
+		Year:  request.Year,
+		Type:  request.Type,
+		Page:  request.Page,
 	}
-	if request.Type != "" {
-		params.Add("type", request.Type)
+	result, err := withFallback(ctx, c.logger, c.providers, func(provider Provider) (model.MovieSearchResult, error) {
+		return provider.Search(ctx, query)
+	})
+	if err != nil {
+		return model.SearchMovieResponse{}, err
 	}
-	return params
+
+	return toSearchMovieResponse(result), nil
 }
 
-func constructParamsForGetMovieDetailsById(request model.AddMovieToCartRequest, appConfig configs.Config) url.Values {
-	params := url.Values{}
-	params.Add("apikey", appConfig.GetApiKey())
+func (c client) GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
+	c.logger.DebugContext(ctx, "looking up movie", "movie_id", request.MovieID, "title", request.Title, "year", request.Year, "type", request.Type)
 
-	if request.MovieID != "" {
-		params.Add("i", request.MovieID)
-	}
-	return params
+	return c.lookup(ctx, LookupQuery{
+		ID:    request.MovieID,
+		Title: request.Title,
+		Year:  request.Year,
+		Type:  request.Type,
+	})
 }
 
-func makeGetRequest(apiUrl string, queryParams url.Values, out any) (err error) {
-	u, err := url.Parse(apiUrl)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-	u.RawQuery = queryParams.Encode()
+func (c client) GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
+	c.logger.DebugContext(ctx, "looking up movie", "movie_id", request.MovieID)
 
-	log.Println("movies req str", u.String())
+	return c.lookup(ctx, LookupQuery{ID: request.MovieID})
+}
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+func (c client) lookup(ctx context.Context, query LookupQuery) (model.GetMovieDetailsResponse, error) {
+	movie, err := withFallback(ctx, c.logger, c.providers, func(provider Provider) (model.MovieMetadata, error) {
+		return provider.Lookup(ctx, query)
+	})
 	if err != nil {
-		return err
+		return model.GetMovieDetailsResponse{}, err
 	}
 
-	req.Header.Set("Accept", "application/json")
+	return toMovieDetailsResponse(movie), nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
+// withFallback calls each provider in turn until one answers. Only provider
+// failures move on to the next provider; a not-found answer, a rejected
+// query or our own cancellation is returned straight away.
+func withFallback[T any](ctx context.Context, logger *slog.Logger, providers []Provider, call func(provider Provider) (T, error)) (T, error) {
+	var resp T
+	var err error
+	for i, provider := range providers {
+		resp, err = call(provider)
+		if !isProviderFailure(err) || ctx.Err() != nil {
+			return resp, err
+		}
+		if i+1 < len(providers) {
+			logger.WarnContext(ctx, "movie provider failed, falling back", "provider", provider.Name(), "fallback", providers[i+1].Name(), "error", err)
+			trace.SpanFromContext(ctx).AddEvent("provider fallback", trace.WithAttributes(
+				attribute.String("movie.provider", provider.Name()),
+				attribute.String("movie.fallback", providers[i+1].Name()),
+			))
+		}
+	}
+	return resp, err
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
-		return err
-	}
+func toSearchMovieResponse(result model.MovieSearchResult) model.SearchMovieResponse {
+	movies := make([]model.Movie, 0, len(result.Movies))
+	for _, movie := range result.Movies {
+		movies = append(movies, model.Movie{
+			Title:  movie.Title,
+			Year:   movie.Year,
+			ImdbID: movie.ID,
+			Type:   movie.Type,
+			Poster: movie.Poster,
+		})
+	}
+	return model.SearchMovieResponse{Movies: movies, Response: "True"}
+}
 
-	return nil
+func toMovieDetailsResponse(movie model.MovieMetadata) model.GetMovieDetailsResponse {
+	return model.GetMovieDetailsResponse{
+		Title:      movie.Title,
+		Year:       movie.Year,
+		Rated:      movie.Rated,
+		Released:   movie.Released,
+		Genre:      movie.Genre,
+		Runtime:    movie.Runtime,
+		Director:   movie.Director,
+		Actors:     movie.Actors,
+		Plot:       movie.Plot,
+		Language:   movie.Language,
+		Country:    movie.Country,
+		Awards:     movie.Awards,
+		Poster:     movie.Poster,
+		Ratings:    movie.Ratings,
+		Metascore:  movie.Metascore,
+		ImdbRating: movie.ImdbRating,
+		ImdbID:     movie.ID,
+		Type:       movie.Type,
+		BoxOffice:  movie.BoxOffice,
+		Production: movie.Production,
+		Website:    movie.Website,
+		Response:   "True",
+	}
 }
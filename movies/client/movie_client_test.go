@@ -1,135 +1,140 @@
 package client
 
 import (
-	"bytes"
+	"context"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
 	"go-movie-api/movies/mock"
 	"go-movie-api/movies/model"
-	"io"
 	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/mock/gomock"
 )
 
-type roundTripperFunc func(*http.Request) *http.Response
-
-func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
-	return f(req), nil
-}
-
-func mockHttpClient(responseBody string, statusCode int) *http.Client {
-	return &http.Client{
-		Transport: roundTripperFunc(func(req *http.Request) *http.Response {
-			return &http.Response{
-				StatusCode: statusCode,
-				Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
-				Header:     make(http.Header),
-			}
-		}),
-	}
+// newStandIn starts a local server that answers every request with handler.
+func newStandIn(t *testing.T, handler func(r *http.Request) (int, string)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, body := handler(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
 }
 
-func TestForSearchMovies(t *testing.T) {
+func newTestClient(t *testing.T, omdbUrl string, providers configs.ProvidersConfig) client {
 	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
 
 	mockCfg := mock.NewMockConfig(ctrl)
-
 	mockCfg.EXPECT().GetApiKey().AnyTimes().Return("mock-key")
+	mockCfg.EXPECT().SearchMoviesUrl().AnyTimes().Return(omdbUrl)
+	mockCfg.EXPECT().GetHttpClientConfig().AnyTimes().Return(configs.HttpClientConfig{})
+	mockCfg.EXPECT().GetProvidersConfig().AnyTimes().Return(providers)
+
+	c, err := NewClient(mockCfg, logging.Discard(), noop.NewTracerProvider())
+	assert.NoError(t, err)
+	t.Cleanup(c.Close)
+	return c
+}
 
-	// Replace http.DefaultClient with a custom client temporarily
-	defaultClient := http.DefaultClient
-	http.DefaultClient = mockHttpClient(`{"Search":[{"Title":"Inception"}]}`, 200)
-	defer func() { http.DefaultClient = defaultClient }()
-
-	c := NewClient(mockCfg)
+func newOmdbClient(t *testing.T, omdbUrl string) client {
+	return newTestClient(t, omdbUrl, configs.ProvidersConfig{Primary: "omdb"})
+}
 
+func TestForSearchMovies(t *testing.T) {
 	t.Run("should return valid response when search movie api is success", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://mock-api/movies")
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.SearchMovieRequest{SearchQuery: "Inception"}
+		server := newStandIn(t, func(r *http.Request) (int, string) {
+			assert.Equal(t, "mock-key", r.URL.Query().Get("apikey"))
+			assert.Equal(t, "Inception", r.URL.Query().Get("s"))
+			assert.Equal(t, "2", r.URL.Query().Get("page"))
+			return 200, `{"Search":[{"Title":"Inception","Year":"2010","imdbID":"tt1375666","Type":"movie","Poster":"N/A"}],"totalResults":"1","Response":"True"}`
+		})
+		c := newOmdbClient(t, server.URL)
 
-		resp, err := c.SearchMovies(ctx, req)
+		resp, err := c.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "Inception", Page: "2"})
 
 		assert.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.GreaterOrEqual(t, len(resp.Movies), 1)
-		assert.Equal(t, "Inception", resp.Movies[0].Title)
+		assert.Equal(t, []model.Movie{{Title: "Inception", Year: "2010", ImdbID: "tt1375666", Type: "movie"}}, resp.Movies)
 	})
 
 	t.Run("should throw error when url is invalid", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://%%invalid-url").Times(1)
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.SearchMovieRequest{SearchQuery: "fail"}
+		c := newOmdbClient(t, "http://%%invalid-url")
 
-		resp, err := c.SearchMovies(ctx, req)
+		resp, err := c.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "fail"})
 
 		assert.Error(t, err)
 		assert.Empty(t, resp.Movies)
 	})
 
 	t.Run("should return http request error for invalid url", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://[invalid-url").Times(1)
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.SearchMovieRequest{SearchQuery: "fail"}
+		c := newOmdbClient(t, "http://[invalid-url")
 
-		resp, err := c.SearchMovies(ctx, req)
+		resp, err := c.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "fail"})
 
 		assert.Error(t, err)
 		assert.Empty(t, resp.Movies)
 	})
 
 	t.Run("should return json decode error if api returns invalid json", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://mock-api/movies").Times(1)
+		server := newStandIn(t, func(r *http.Request) (int, string) {
+			return 200, "not json"
+		})
+		c := newOmdbClient(t, server.URL)
 
-		http.DefaultClient = mockHttpClient("not json", 200)
+		resp, err := c.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "fail"})
 
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.SearchMovieRequest{SearchQuery: "fail"}
-
-		resp, err := c.SearchMovies(ctx, req)
-
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrMalformedResponse)
 		assert.Empty(t, resp.Movies)
 	})
 }
 
 func TestForGetMovieDetails(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockCfg := mock.NewMockConfig(ctrl)
-
-	mockCfg.EXPECT().GetApiKey().AnyTimes().Return("mock-key")
+	t.Run("should get the movie details based on the request", func(t *testing.T) {
+		server := newStandIn(t, func(r *http.Request) (int, string) {
+			assert.Equal(t, "tt1375666", r.URL.Query().Get("i"))
+			return 200, `{"Title":"Inception","Year":"2010","Genre":"Sci-Fi","Awards":"N/A","imdbID":"tt1375666","Response":"True"}`
+		})
+		c := newOmdbClient(t, server.URL)
 
-	defaultClient := http.DefaultClient
-	http.DefaultClient = mockHttpClient(`{"Search":[{"Title":"Inception"}]}`, 200)
-	defer func() { http.DefaultClient = defaultClient }()
+		resp, err := c.GetMovieDetails(context.Background(), model.GetMovieDetailsRequest{MovieID: "tt1375666"})
 
-	c := NewClient(mockCfg)
+		assert.NoError(t, err)
+		assert.Equal(t, "Inception", resp.Title)
+		assert.Equal(t, "Sci-Fi", resp.Genre)
+		assert.Equal(t, "tt1375666", resp.ImdbID)
+		assert.Empty(t, resp.Awards)
+	})
 
-	t.Run("should get the movie details based on the request", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://mock-api/movies")
-		http.DefaultClient = mockHttpClient(`{"Title":"Inception","Year":"2010","Genre":"Sci-Fi","ImdbID":"tt1375666"}`, 200)
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.GetMovieDetailsRequest{MovieID: "tt1375666"}
+	t.Run("should look the movie up by title", func(t *testing.T) {
+		server := newStandIn(t, func(r *http.Request) (int, string) {
+			assert.Equal(t, "Inception", r.URL.Query().Get("t"))
+			assert.Equal(t, "2010", r.URL.Query().Get("y"))
+			assert.False(t, r.URL.Query().Has("i"))
+			return 200, `{"Title":"Inception","imdbID":"tt1375666","Response":"True"}`
+		})
+		c := newOmdbClient(t, server.URL)
 
-		resp, err := c.GetMovieDetails(ctx, req)
+		resp, err := c.GetMovieDetails(context.Background(), model.GetMovieDetailsRequest{Title: "Inception", Year: "2010"})
 
 		assert.NoError(t, err)
-		assert.Equal(t, "Inception", resp.Title)
 		assert.Equal(t, "tt1375666", resp.ImdbID)
 	})
 
 	t.Run("should return json decode error if invalid json is received from the api", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://mock-api/movies")
-		http.DefaultClient = mockHttpClient("invalid json", 200)
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.GetMovieDetailsRequest{MovieID: "tt1375666"}
+		server := newStandIn(t, func(r *http.Request) (int, string) {
+			return 200, "invalid json"
+		})
+		c := newOmdbClient(t, server.URL)
 
-		resp, err := c.GetMovieDetails(ctx, req)
+		resp, err := c.GetMovieDetails(context.Background(), model.GetMovieDetailsRequest{MovieID: "tt1375666"})
 
 		assert.Error(t, err)
 		assert.Empty(t, resp.Title)
@@ -137,42 +142,189 @@ func TestForGetMovieDetails(t *testing.T) {
 }
 
 func TestForGetMovieDetailsById(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+	server := newStandIn(t, func(r *http.Request) (int, string) {
+		assert.Equal(t, "tt0133093", r.URL.Query().Get("i"))
+		return 200, `{"Title":"Matrix","Year":"1999","Genre":"Action","imdbID":"tt0133093","Response":"True"}`
+	})
+	c := newOmdbClient(t, server.URL)
 
-	mockCfg := mock.NewMockConfig(ctrl)
+	resp, err := c.GetMovieDetailsById(context.Background(), model.AddMovieToCartRequest{MovieID: "tt0133093"})
 
-	mockCfg.EXPECT().GetApiKey().AnyTimes().Return("mock-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "Matrix", resp.Title)
+	assert.Equal(t, "tt0133093", resp.ImdbID)
+}
+
+func TestRequestIsCancelledWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c := newOmdbClient(t, server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.SearchMovies(ctx, model.SearchMovieRequest{SearchQuery: "Inception"})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestForProviderErrors(t *testing.T) {
+	body := ""
+	server := newStandIn(t, func(r *http.Request) (int, string) {
+		return 200, body
+	})
+	c := newOmdbClient(t, server.URL)
+
+	t.Run("should return not found when omdb cannot find the movie", func(t *testing.T) {
+		body = `{"Response":"False","Error":"Movie not found!"}`
+
+		_, err := c.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "zzzz"})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("should return not found for an unknown imdb id", func(t *testing.T) {
+		body = `{"Response":"False","Error":"Incorrect IMDb ID."}`
+
+		_, err := c.GetMovieDetailsById(context.Background(), model.AddMovieToCartRequest{MovieID: "tt0"})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
 
-	// Replace http.DefaultClient with a custom client temporarily
-	defaultClient := http.DefaultClient
-	http.DefaultClient = mockHttpClient(`{"Search":[{"Title":"Inception"}]}`, 200)
-	defer func() { http.DefaultClient = defaultClient }()
+	t.Run("should return rate limited when the daily limit is reached", func(t *testing.T) {
+		body = `{"Response":"False","Error":"Request limit reached!"}`
 
-	c := NewClient(mockCfg)
+		_, err := c.GetMovieDetails(context.Background(), model.GetMovieDetailsRequest{MovieID: "tt1375666"})
 
-	t.Run("should get the movie details based on the movie id", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://mock-api/movies")
-		http.DefaultClient = mockHttpClient(`{"Title":"Matrix","Year":"1999","Genre":"Action","ImdbID":"tt0133093"}`, 200)
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.AddMovieToCartRequest{MovieID: "tt0133093"}
+		assert.ErrorIs(t, err, ErrRateLimited)
+	})
+
+	t.Run("should return other omdb errors as they are", func(t *testing.T) {
+		body = `{"Response":"False","Error":"Too many results."}`
+
+		_, err := c.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "a"})
+
+		assert.EqualError(t, err, "Too many results.")
+		assert.False(t, isProviderFailure(err))
+	})
+
+	t.Run("should return malformed response for invalid json", func(t *testing.T) {
+		body = "invalid json"
 
-		resp, err := c.GetMovieDetailsById(ctx, req)
+		_, err := c.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "a"})
+
+		assert.ErrorIs(t, err, ErrMalformedResponse)
+	})
+}
+
+func TestProviderFallback(t *testing.T) {
+	var omdbStatus atomic.Int32
+	var omdbBody atomic.Value
+	omdb := newStandIn(t, func(r *http.Request) (int, string) {
+		return int(omdbStatus.Load()), omdbBody.Load().(string)
+	})
+
+	tmdb := newStandIn(t, func(r *http.Request) (int, string) {
+		assert.Equal(t, "tmdb-key", r.URL.Query().Get("api_key"))
+		if r.URL.Path == "/find/tt1375666" {
+			return 200, `{"movie_results":[{"id":27205,"title":"Inception"}]}`
+		}
+		assert.Equal(t, "/movie/27205", r.URL.Path)
+		return 200, `{"id":27205,"imdb_id":"tt1375666","title":"Inception","release_date":"2010-07-15"}`
+	})
+
+	c := newTestClient(t, omdb.URL, configs.ProvidersConfig{
+		Primary:  "omdb",
+		Fallback: "tmdb",
+		Tmdb:     configs.TmdbConfig{ApiKey: "tmdb-key", BaseUrl: tmdb.URL},
+	})
+	req := model.AddMovieToCartRequest{MovieID: "tt1375666"}
+
+	t.Run("should use the primary provider while it is healthy", func(t *testing.T) {
+		omdbStatus.Store(200)
+		omdbBody.Store(`{"Title":"Inception","Year":"2010","imdbID":"tt1375666","Response":"True"}`)
+
+		resp, err := c.GetMovieDetailsById(context.Background(), req)
 
 		assert.NoError(t, err)
-		assert.Equal(t, "Matrix", resp.Title)
-		assert.Equal(t, "tt0133093", resp.ImdbID)
+		assert.Equal(t, "2010", resp.Year)
+		assert.Empty(t, resp.Released)
 	})
 
-	t.Run("should return json decode error if invalid json is received from the api", func(t *testing.T) {
-		mockCfg.EXPECT().SearchMoviesUrl().Return("http://mock-api/movies")
-		http.DefaultClient = mockHttpClient("invalid json", 200)
-		ctx, _ := gin.CreateTestContext(nil)
-		req := model.AddMovieToCartRequest{MovieID: "tt0133093"}
+	t.Run("should fall back when the primary provider fails", func(t *testing.T) {
+		omdbStatus.Store(503)
+		omdbBody.Store(`{}`)
 
-		resp, err := c.GetMovieDetailsById(ctx, req)
+		resp, err := c.GetMovieDetailsById(context.Background(), req)
 
-		assert.Error(t, err)
-		assert.Empty(t, resp.Title)
+		assert.NoError(t, err)
+		assert.Equal(t, "Inception", resp.Title)
+		assert.Equal(t, "tt1375666", resp.ImdbID)
+		assert.Equal(t, "15 Jul 2010", resp.Released)
 	})
+
+	t.Run("should not fall back when the primary provider has no such movie", func(t *testing.T) {
+		omdbStatus.Store(200)
+		omdbBody.Store(`{"Response":"False","Error":"Incorrect IMDb ID."}`)
+
+		_, err := c.GetMovieDetailsById(context.Background(), model.AddMovieToCartRequest{MovieID: "tt0"})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("should return the last error when every provider fails", func(t *testing.T) {
+		omdbStatus.Store(503)
+		omdbBody.Store(`{}`)
+		failing := newTestClient(t, omdb.URL, configs.ProvidersConfig{
+			Primary:  "omdb",
+			Fallback: "tmdb",
+			Tmdb:     configs.TmdbConfig{BaseUrl: newStandIn(t, func(r *http.Request) (int, string) { return 401, `{"status_code":7}` }).URL},
+		})
+
+		_, err := failing.SearchMovies(context.Background(), model.SearchMovieRequest{SearchQuery: "Inception"})
+
+		var upstreamErr *UpstreamError
+		assert.ErrorAs(t, err, &upstreamErr)
+		assert.Equal(t, 401, upstreamErr.StatusCode)
+	})
+}
+
+func TestNewClientRejectsUnknownProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockCfg := mock.NewMockConfig(ctrl)
+	mockCfg.EXPECT().GetApiKey().AnyTimes().Return("mock-key")
+	mockCfg.EXPECT().SearchMoviesUrl().AnyTimes().Return("http://mock-api/movies")
+	mockCfg.EXPECT().GetHttpClientConfig().AnyTimes().Return(configs.HttpClientConfig{})
+	mockCfg.EXPECT().GetProvidersConfig().AnyTimes().Return(configs.ProvidersConfig{Primary: "omdb", Fallback: "imdb"})
+
+	_, err := NewClient(mockCfg, logging.Discard(), noop.NewTracerProvider())
+
+	assert.EqualError(t, err, `unknown movie provider "imdb"`)
+}
+
+func TestReachability(t *testing.T) {
+	omdb := newStandIn(t, func(r *http.Request) (int, string) {
+		t.Error("reachability shouldn't send requests")
+		return 200, `{}`
+	})
+	c := newTestClient(t, omdb.URL, configs.ProvidersConfig{
+		Primary:  "omdb",
+		Fallback: "tmdb",
+		Tmdb:     configs.TmdbConfig{BaseUrl: "http://127.0.0.1:1"},
+	})
+
+	results := c.Reachability(context.Background())
+
+	assert.Len(t, results, 2)
+	assert.NoError(t, results["omdb"])
+	assert.Error(t, results["tmdb"])
 }
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/movies/model"
+	"net/url"
+)
+
+type omdbProvider struct {
+	apiKey     string
+	apiUrl     string
+	httpClient *HttpClient
+}
+
+// NewOmdbProvider returns a Provider backed by the OMDb API at apiUrl.
+func NewOmdbProvider(apiKey string, apiUrl string, httpClient *HttpClient) Provider {
+	return omdbProvider{apiKey: apiKey, apiUrl: apiUrl, httpClient: httpClient}
+}
+
+type omdbSearchResponse struct {
+	Search   []omdbMovie `json:"Search"`
+	Response string      `json:"Response"`
+	Error    string      `json:"Error"`
+}
+
+type omdbMovie struct {
+	Title  string `json:"Title"`
+	Year   string `json:"Year"`
+	ImdbID string `json:"imdbID"`
+	Type   string `json:"Type"`
+	Poster string `json:"Poster"`
+}
+
+type omdbDetailsResponse struct {
+	Title      string         `json:"Title"`
+	Year       string         `json:"Year"`
+	Rated      string         `json:"Rated"`
+	Released   string         `json:"Released"`
+	Runtime    string         `json:"Runtime"`
+	Genre      string         `json:"Genre"`
+	Director   string         `json:"Director"`
+	Actors     string         `json:"Actors"`
+	Plot       string         `json:"Plot"`
+	Language   string         `json:"Language"`
+	Country    string         `json:"Country"`
+	Awards     string         `json:"Awards"`
+	Poster     string         `json:"Poster"`
+	Ratings    []model.Rating `json:"Ratings"`
+	Metascore  string         `json:"Metascore"`
+	ImdbRating string         `json:"imdbRating"`
+	ImdbID     string         `json:"imdbID"`
+	Type       string         `json:"Type"`
+	BoxOffice  string         `json:"BoxOffice"`
+	Production string         `json:"Production"`
+	Website    string         `json:"Website"`
+	Response   string         `json:"Response"`
+	Error      string         `json:"Error"`
+}
+
+func (p omdbProvider) Name() string {
+	return "omdb"
+}
+
+func (p omdbProvider) Endpoint() string {
+	return p.apiUrl
+}
+
+func (p omdbProvider) Search(ctx context.Context, query SearchQuery) (model.MovieSearchResult, error) {
+	var resp omdbSearchResponse
+	if err := p.httpClient.Get(ctx, p.apiUrl, p.searchParams(query), &resp); err != nil {
+		return model.MovieSearchResult{}, err
+	}
+	if err := omdbError(resp.Error); err != nil {
+		return model.MovieSearchResult{}, err
+	}
+
+	result := model.MovieSearchResult{Movies: make([]model.MovieSummary, 0, len(resp.Search))}
+	for _, movie := range resp.Search {
+		result.Movies = append(result.Movies, model.MovieSummary{
+			ID:     movie.ImdbID,
+			Title:  movie.Title,
+			Year:   movie.Year,
+			Type:   movie.Type,
+			Poster: omdbValue(movie.Poster),
+		})
+	}
+	return result, nil
+}
+
+func (p omdbProvider) Lookup(ctx context.Context, query LookupQuery) (model.MovieMetadata, error) {
+	var resp omdbDetailsResponse
+	if err := p.httpClient.Get(ctx, p.apiUrl, p.lookupParams(query), &resp); err != nil {
+		return model.MovieMetadata{}, err
+	}
+	if err := omdbError(resp.Error); err != nil {
+		return model.MovieMetadata{}, err
+	}
+
+	return model.MovieMetadata{
+		ID:         resp.ImdbID,
+		Title:      resp.Title,
+		Year:       resp.Year,
+		Rated:      omdbValue(resp.Rated),
+		Released:   omdbValue(resp.Released),
+		Genre:      omdbValue(resp.Genre),
+		Runtime:    omdbValue(resp.Runtime),
+		Director:   omdbValue(resp.Director),
+		Actors:     omdbValue(resp.Actors),
+		Plot:       omdbValue(resp.Plot),
+		Language:   omdbValue(resp.Language),
+		Country:    omdbValue(resp.Country),
+		Awards:     omdbValue(resp.Awards),
+		Poster:     omdbValue(resp.Poster),
+		Ratings:    resp.Ratings,
+		Metascore:  omdbValue(resp.Metascore),
+		ImdbRating: omdbValue(resp.ImdbRating),
+		Type:       resp.Type,
+		BoxOffice:  omdbValue(resp.BoxOffice),
+		Production: omdbValue(resp.Production),
+		Website:    omdbValue(resp.Website),
+	}, nil
+}
+
+func (p omdbProvider) searchParams(query SearchQuery) url.Values {
+	params := url.Values{}
+	params.Add("apikey", p.apiKey)
+	params.Add("s", query.Text)
+
+	if query.Title != "" {
+		params.Add("t", query.Title)
+	}
+	if query.Year != "" {
+		params.Add("y", query.Year)
+	}
+	if query.Type != "" {
+		params.Add("type", query.Type)
+	}
+	if query.Page != "" {
+		params.Add("page", query.Page)
+	}
+	return params
+}
+
+func (p omdbProvider) lookupParams(query LookupQuery) url.Values {
+	params := url.Values{}
+	params.Add("apikey", p.apiKey)
+
+	if query.ID != "" {
+		params.Add("i", query.ID)
+	}
+	if query.Title != "" {
+		params.Add("t", query.Title)
+	}
+	if query.Year != "" {
+		params.Add("y", query.Year)
+	}
+	if query.Type != "" {
+		params.Add("type", query.Type)
+	}
+	return params
+}
+
+// omdbValue drops the "N/A" OMDb uses for fields it has no value for.
+func omdbValue(value string) string {
+	if value == "N/A" {
+		return ""
+	}
+	return value
+}
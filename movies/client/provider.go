@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/movies/model"
+)
+
+// Provider is a source of movie metadata. Implementations translate the
+// neutral queries into their own API and map the answers into the neutral
+// model. Failures are returned as *UpstreamError, like HttpClient does.
+type Provider interface {
+	Name() string
+	// Endpoint is the base url of the provider's API.
+	Endpoint() string
+	Search(ctx context.Context, query SearchQuery) (model.MovieSearchResult, error)
+	Lookup(ctx context.Context, query LookupQuery) (model.MovieMetadata, error)
+}
+
+// SearchQuery asks for a page of movies matching Text. Type is "movie",
+// "series" or "episode"; Page starts at 1.
+type SearchQuery struct {
+	Text  string
+	Title string
+	Year  string
+	Type  string
+	Page  string
+}
+
+// LookupQuery identifies a single movie, either by ID or by Title.
+type LookupQuery struct {
+	ID    string
+	Title string
+	Year  string
+	Type  string
+}
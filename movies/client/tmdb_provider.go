@@ -0,0 +1,353 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/movies/model"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tmdbMovie = "movie"
+	tmdbTV    = "tv"
+
+	// tmdbIdPrefix marks ids of titles that TMDB search returned. Search hits
+	// don't carry IMDb ids, so they're given as "tmdb:movie:27205" and Lookup
+	// accepts them back.
+	tmdbIdPrefix = "tmdb:"
+
+	tmdbMaxActors = 4
+)
+
+type tmdbProvider struct {
+	apiKey       string
+	baseUrl      string
+	imageBaseUrl string
+	httpClient   *HttpClient
+}
+
+// NewTmdbProvider returns a Provider backed by the TMDB v3 API.
+func NewTmdbProvider(cfg configs.TmdbConfig, httpClient *HttpClient) Provider {
+	return tmdbProvider{
+		apiKey:       cfg.ApiKey,
+		baseUrl:      strings.TrimSuffix(cfg.BaseUrl, "/"),
+		imageBaseUrl: strings.TrimSuffix(cfg.ImageBaseUrl, "/"),
+		httpClient:   httpClient,
+	}
+}
+
+type tmdbSearchResponse struct {
+	Results []tmdbSearchResult `json:"results"`
+}
+
+type tmdbSearchResult struct {
+	ID           int    `json:"id"`
+	MediaType    string `json:"media_type"`
+	Title        string `json:"title"`
+	Name         string `json:"name"`
+	ReleaseDate  string `json:"release_date"`
+	FirstAirDate string `json:"first_air_date"`
+	PosterPath   string `json:"poster_path"`
+}
+
+type tmdbFindResponse struct {
+	MovieResults []tmdbSearchResult `json:"movie_results"`
+	TVResults    []tmdbSearchResult `json:"tv_results"`
+}
+
+type tmdbName struct {
+	Name string `json:"name"`
+}
+
+type tmdbLanguage struct {
+	EnglishName string `json:"english_name"`
+}
+
+type tmdbCredits struct {
+	Cast []tmdbName `json:"cast"`
+	Crew []struct {
+		Name string `json:"name"`
+		Job  string `json:"job"`
+	} `json:"crew"`
+}
+
+type tmdbDetailsResponse struct {
+	ID                  int            `json:"id"`
+	ImdbID              string         `json:"imdb_id"`
+	Title               string         `json:"title"`
+	Name                string         `json:"name"`
+	ReleaseDate         string         `json:"release_date"`
+	FirstAirDate        string         `json:"first_air_date"`
+	Runtime             int            `json:"runtime"`
+	EpisodeRunTime      []int          `json:"episode_run_time"`
+	Genres              []tmdbName     `json:"genres"`
+	Overview            string         `json:"overview"`
+	SpokenLanguages     []tmdbLanguage `json:"spoken_languages"`
+	ProductionCountries []tmdbName     `json:"production_countries"`
+	ProductionCompanies []tmdbName     `json:"production_companies"`
+	CreatedBy           []tmdbName     `json:"created_by"`
+	PosterPath          string         `json:"poster_path"`
+	VoteAverage         float64        `json:"vote_average"`
+	VoteCount           int            `json:"vote_count"`
+	Homepage            string         `json:"homepage"`
+	Credits             tmdbCredits    `json:"credits"`
+	ExternalIDs         struct {
+		ImdbID string `json:"imdb_id"`
+	} `json:"external_ids"`
+}
+
+func (p tmdbProvider) Name() string {
+	return "tmdb"
+}
+
+func (p tmdbProvider) Endpoint() string {
+	return p.baseUrl
+}
+
+// Search uses the movie or tv search for a typed query and the multi search
+// otherwise. TMDB has no title filter besides the query itself, so Title is
+// ignored.
+func (p tmdbProvider) Search(ctx context.Context, query SearchQuery) (model.MovieSearchResult, error) {
+	params := p.params()
+	params.Set("query", query.Text)
+	if query.Page != "" {
+		params.Set("page", query.Page)
+	}
+
+	var mediaType string
+	switch query.Type {
+	case "":
+		// the multi search has no year filter, the results are filtered below
+	case "movie":
+		mediaType = tmdbMovie
+		if query.Year != "" {
+			params.Set("year", query.Year)
+		}
+	case "series":
+		mediaType = tmdbTV
+		if query.Year != "" {
+			params.Set("first_air_date_year", query.Year)
+		}
+	default:
+		return model.MovieSearchResult{}, &UpstreamError{Kind: ErrNotFound, Err: fmt.Errorf("tmdb can't search for type %q", query.Type)}
+	}
+
+	path := "/search/multi"
+	if mediaType != "" {
+		path = "/search/" + mediaType
+	}
+
+	var resp tmdbSearchResponse
+	if err := p.httpClient.Get(ctx, p.baseUrl+path, params, &resp); err != nil {
+		return model.MovieSearchResult{}, err
+	}
+
+	result := model.MovieSearchResult{Movies: make([]model.MovieSummary, 0, len(resp.Results))}
+	for _, hit := range resp.Results {
+		if mediaType != "" {
+			hit.MediaType = mediaType
+		}
+		if hit.MediaType != tmdbMovie && hit.MediaType != tmdbTV {
+			continue
+		}
+
+		movie := p.summary(hit)
+		if query.Year != "" && movie.Year != query.Year {
+			continue
+		}
+		result.Movies = append(result.Movies, movie)
+	}
+
+	if len(result.Movies) == 0 {
+		return model.MovieSearchResult{}, &UpstreamError{Kind: ErrNotFound, Err: errors.New("no results for " + strconv.Quote(query.Text))}
+	}
+	return result, nil
+}
+
+// Lookup resolves the query to a TMDB title, by IMDb id, by the ids Search
+// hands out or by searching for the title, and then fetches its details.
+func (p tmdbProvider) Lookup(ctx context.Context, query LookupQuery) (model.MovieMetadata, error) {
+	mediaType, id, err := p.resolve(ctx, query)
+	if err != nil {
+		return model.MovieMetadata{}, err
+	}
+
+	params := p.params()
+	params.Set("append_to_response", "credits,external_ids")
+
+	var resp tmdbDetailsResponse
+	if err := p.httpClient.Get(ctx, fmt.Sprintf("%s/%s/%d", p.baseUrl, mediaType, id), params, &resp); err != nil {
+		return model.MovieMetadata{}, err
+	}
+
+	return p.metadata(mediaType, resp), nil
+}
+
+func (p tmdbProvider) resolve(ctx context.Context, query LookupQuery) (string, int, error) {
+	if mediaType, id, ok := parseTmdbId(query.ID); ok {
+		return mediaType, id, nil
+	}
+
+	if query.ID != "" {
+		params := p.params()
+		params.Set("external_source", "imdb_id")
+
+		var resp tmdbFindResponse
+		if err := p.httpClient.Get(ctx, p.baseUrl+"/find/"+url.PathEscape(query.ID), params, &resp); err != nil {
+			return "", 0, err
+		}
+		switch {
+		case len(resp.MovieResults) > 0:
+			return tmdbMovie, resp.MovieResults[0].ID, nil
+		case len(resp.TVResults) > 0:
+			return tmdbTV, resp.TVResults[0].ID, nil
+		default:
+			return "", 0, &UpstreamError{Kind: ErrNotFound, Err: errors.New("no tmdb title for imdb id " + query.ID)}
+		}
+	}
+
+	if query.Title != "" {
+		result, err := p.Search(ctx, SearchQuery{Text: query.Title, Year: query.Year, Type: query.Type})
+		if err != nil {
+			return "", 0, err
+		}
+		if mediaType, id, ok := parseTmdbId(result.Movies[0].ID); ok {
+			return mediaType, id, nil
+		}
+	}
+
+	return "", 0, &UpstreamError{Kind: ErrNotFound, Err: errors.New("no movie id or title given")}
+}
+
+func (p tmdbProvider) params() url.Values {
+	params := url.Values{}
+	params.Set("api_key", p.apiKey)
+	return params
+}
+
+func (p tmdbProvider) summary(hit tmdbSearchResult) model.MovieSummary {
+	return model.MovieSummary{
+		ID:     tmdbId(hit.MediaType, hit.ID),
+		Title:  firstNonEmpty(hit.Title, hit.Name),
+		Year:   yearOf(firstNonEmpty(hit.ReleaseDate, hit.FirstAirDate)),
+		Type:   tmdbType(hit.MediaType),
+		Poster: p.poster(hit.PosterPath),
+	}
+}
+
+func (p tmdbProvider) metadata(mediaType string, resp tmdbDetailsResponse) model.MovieMetadata {
+	movie := model.MovieMetadata{
+		ID:         firstNonEmpty(resp.ImdbID, resp.ExternalIDs.ImdbID, tmdbId(mediaType, resp.ID)),
+		Title:      firstNonEmpty(resp.Title, resp.Name),
+		Genre:      joinNames(resp.Genres),
+		Plot:       resp.Overview,
+		Country:    joinNames(resp.ProductionCountries),
+		Production: joinNames(resp.ProductionCompanies),
+		Poster:     p.poster(resp.PosterPath),
+		Type:       tmdbType(mediaType),
+		Website:    resp.Homepage,
+	}
+
+	released := firstNonEmpty(resp.ReleaseDate, resp.FirstAirDate)
+	movie.Year = yearOf(released)
+	if date, err := time.Parse(time.DateOnly, released); err == nil {
+		movie.Released = date.Format("02 Jan 2006")
+	}
+
+	runtime := resp.Runtime
+	if runtime == 0 && len(resp.EpisodeRunTime) > 0 {
+		runtime = resp.EpisodeRunTime[0]
+	}
+	if runtime > 0 {
+		movie.Runtime = strconv.Itoa(runtime) + " min"
+	}
+
+	languages := make([]string, 0, len(resp.SpokenLanguages))
+	for _, language := range resp.SpokenLanguages {
+		languages = append(languages, language.EnglishName)
+	}
+	movie.Language = strings.Join(languages, ", ")
+
+	directors := []string{}
+	for _, member := range resp.Credits.Crew {
+		if member.Job == "Director" {
+			directors = append(directors, member.Name)
+		}
+	}
+	movie.Director = strings.Join(directors, ", ")
+	if movie.Director == "" {
+		movie.Director = joinNames(resp.CreatedBy)
+	}
+
+	movie.Actors = joinNames(resp.Credits.Cast[:min(len(resp.Credits.Cast), tmdbMaxActors)])
+
+	if resp.VoteCount > 0 {
+		movie.Ratings = []model.Rating{{Source: "The Movie Database", Value: fmt.Sprintf("%.1f/10", resp.VoteAverage)}}
+	}
+
+	return movie
+}
+
+func (p tmdbProvider) poster(path string) string {
+	if path == "" {
+		return ""
+	}
+	return p.imageBaseUrl + path
+}
+
+func tmdbId(mediaType string, id int) string {
+	return fmt.Sprintf("%s%s:%d", tmdbIdPrefix, mediaType, id)
+}
+
+func parseTmdbId(value string) (string, int, bool) {
+	rest, ok := strings.CutPrefix(value, tmdbIdPrefix)
+	if !ok {
+		return "", 0, false
+	}
+	mediaType, rawId, ok := strings.Cut(rest, ":")
+	if !ok || (mediaType != tmdbMovie && mediaType != tmdbTV) {
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(rawId)
+	if err != nil {
+		return "", 0, false
+	}
+	return mediaType, id, true
+}
+
+// tmdbType maps TMDB media types to the types OMDb uses.
+func tmdbType(mediaType string) string {
+	if mediaType == tmdbTV {
+		return "series"
+	}
+	return mediaType
+}
+
+func yearOf(date string) string {
+	if len(date) < 4 {
+		return ""
+	}
+	return date[:4]
+}
+
+func joinNames(names []tmdbName) string {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, name.Name)
+	}
+	return strings.Join(values, ", ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
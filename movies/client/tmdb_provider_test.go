@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/model"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newTestTmdbProvider(t *testing.T, handler func(r *http.Request) (int, string)) Provider {
+	server := newStandIn(t, handler)
+	httpClient := NewHttpClient(configs.HttpClientConfig{}, logging.Discard(), noop.NewTracerProvider())
+	t.Cleanup(httpClient.Close)
+
+	return NewTmdbProvider(configs.TmdbConfig{
+		ApiKey:       "tmdb-key",
+		BaseUrl:      server.URL + "/",
+		ImageBaseUrl: "https://image.tmdb.org/t/p/w500",
+	}, httpClient)
+}
+
+func TestTmdbProviderSearch(t *testing.T) {
+	t.Run("should map multi search hits and skip people", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			assert.Equal(t, "/search/multi", r.URL.Path)
+			assert.Equal(t, "tmdb-key", r.URL.Query().Get("api_key"))
+			assert.Equal(t, "batman", r.URL.Query().Get("query"))
+			return 200, `{"page":1,"results":[
+				{"id":268,"media_type":"movie","title":"Batman","release_date":"1989-06-23","poster_path":"/batman.jpg"},
+				{"id":3108,"media_type":"person","name":"Adam West"},
+				{"id":2287,"media_type":"tv","name":"Batman","first_air_date":"1966-01-12"}]}`
+		})
+
+		result, err := p.Search(context.Background(), SearchQuery{Text: "batman"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.MovieSummary{
+			{ID: "tmdb:movie:268", Title: "Batman", Year: "1989", Type: "movie", Poster: "https://image.tmdb.org/t/p/w500/batman.jpg"},
+			{ID: "tmdb:tv:2287", Title: "Batman", Year: "1966", Type: "series"},
+		}, result.Movies)
+	})
+
+	t.Run("should search series by first air year", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			assert.Equal(t, "/search/tv", r.URL.Path)
+			assert.Equal(t, "2008", r.URL.Query().Get("first_air_date_year"))
+			assert.Equal(t, "2", r.URL.Query().Get("page"))
+			return 200, `{"results":[{"id":1396,"name":"Breaking Bad","first_air_date":"2008-01-20"}]}`
+		})
+
+		result, err := p.Search(context.Background(), SearchQuery{Text: "breaking bad", Type: "series", Year: "2008", Page: "2"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "tmdb:tv:1396", result.Movies[0].ID)
+		assert.Equal(t, "series", result.Movies[0].Type)
+	})
+
+	t.Run("should return not found when nothing matches", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			return 200, `{"results":[{"id":268,"media_type":"movie","title":"Batman","release_date":"1989-06-23"}]}`
+		})
+
+		// the multi search can't filter by year, so the hit from 1989 is dropped
+		_, err := p.Search(context.Background(), SearchQuery{Text: "batman", Year: "2005"})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestTmdbProviderLookup(t *testing.T) {
+	details := `{"id":27205,"imdb_id":"tt1375666","title":"Inception","release_date":"2010-07-15","runtime":148,
+		"genres":[{"name":"Action"},{"name":"Science Fiction"}],"overview":"A thief who steals corporate secrets.",
+		"spoken_languages":[{"english_name":"English"},{"english_name":"Japanese"}],
+		"production_countries":[{"name":"United States of America"}],"production_companies":[{"name":"Legendary Pictures"}],
+		"poster_path":"/inception.jpg","vote_average":8.369,"vote_count":35000,
+		"credits":{"cast":[{"name":"Leonardo DiCaprio"},{"name":"Joseph Gordon-Levitt"},{"name":"Ken Watanabe"},{"name":"Tom Hardy"},{"name":"Elliot Page"}],
+		"crew":[{"name":"Hans Zimmer","job":"Original Music Composer"},{"name":"Christopher Nolan","job":"Director"}]}}`
+
+	t.Run("should find the movie by imdb id and map its details", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			switch r.URL.Path {
+			case "/find/tt1375666":
+				assert.Equal(t, "imdb_id", r.URL.Query().Get("external_source"))
+				return 200, `{"movie_results":[{"id":27205}],"tv_results":[]}`
+			case "/movie/27205":
+				assert.Equal(t, "credits,external_ids", r.URL.Query().Get("append_to_response"))
+				return 200, details
+			}
+			return 404, `{"status_code":34}`
+		})
+
+		movie, err := p.Lookup(context.Background(), LookupQuery{ID: "tt1375666"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.MovieMetadata{
+			ID:         "tt1375666",
+			Title:      "Inception",
+			Year:       "2010",
+			Released:   "15 Jul 2010",
+			Genre:      "Action, Science Fiction",
+			Runtime:    "148 min",
+			Director:   "Christopher Nolan",
+			Actors:     "Leonardo DiCaprio, Joseph Gordon-Levitt, Ken Watanabe, Tom Hardy",
+			Plot:       "A thief who steals corporate secrets.",
+			Language:   "English, Japanese",
+			Country:    "United States of America",
+			Poster:     "https://image.tmdb.org/t/p/w500/inception.jpg",
+			Ratings:    []model.Rating{{Source: "The Movie Database", Value: "8.4/10"}},
+			Type:       "movie",
+			Production: "Legendary Pictures",
+		}, movie)
+	})
+
+	t.Run("should look up series by the ids search hands out", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			assert.Equal(t, "/tv/1396", r.URL.Path)
+			return 200, `{"id":1396,"name":"Breaking Bad","first_air_date":"2008-01-20","episode_run_time":[47],
+				"created_by":[{"name":"Vince Gilligan"}],"external_ids":{"imdb_id":"tt0903747"}}`
+		})
+
+		movie, err := p.Lookup(context.Background(), LookupQuery{ID: "tmdb:tv:1396"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "tt0903747", movie.ID)
+		assert.Equal(t, "series", movie.Type)
+		assert.Equal(t, "47 min", movie.Runtime)
+		assert.Equal(t, "Vince Gilligan", movie.Director)
+	})
+
+	t.Run("should look up by title through search", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			switch r.URL.Path {
+			case "/search/movie":
+				assert.Equal(t, "Inception", r.URL.Query().Get("query"))
+				assert.Equal(t, "2010", r.URL.Query().Get("year"))
+				return 200, `{"results":[{"id":27205,"title":"Inception","release_date":"2010-07-15"}]}`
+			case "/movie/27205":
+				return 200, details
+			}
+			return 404, `{"status_code":34}`
+		})
+
+		movie, err := p.Lookup(context.Background(), LookupQuery{Title: "Inception", Year: "2010", Type: "movie"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "tt1375666", movie.ID)
+	})
+
+	t.Run("should return not found for an unknown imdb id", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			return 200, `{"movie_results":[],"tv_results":[]}`
+		})
+
+		_, err := p.Lookup(context.Background(), LookupQuery{ID: "tt0"})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("should return not found when tmdb answers 404", func(t *testing.T) {
+		p := newTestTmdbProvider(t, func(r *http.Request) (int, string) {
+			return 404, `{"status_code":34,"status_message":"The resource you requested could not be found."}`
+		})
+
+		_, err := p.Lookup(context.Background(), LookupQuery{ID: "tmdb:movie:1"})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestParseTmdbId(t *testing.T) {
+	mediaType, id, ok := parseTmdbId("tmdb:movie:27205")
+	assert.True(t, ok)
+	assert.Equal(t, "movie", mediaType)
+	assert.Equal(t, 27205, id)
+
+	for _, value := range []string{"tt1375666", "tmdb:person:1", "tmdb:movie:abc", "movie:27205"} {
+		_, _, ok := parseTmdbId(value)
+		assert.False(t, ok, value)
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracingClient struct {
+	next   Client
+	tracer trace.Tracer
+}
+
+// NewTracingClient wraps next so that every call gets a span. Calls to the
+// providers made underneath show up as its children.
+func NewTracingClient(next Client, provider trace.TracerProvider) Client {
+	return tracingClient{next: next, tracer: tracing.Tracer(provider)}
+}
+
+func (tc tracingClient) SearchMovies(ctx context.Context, request model.SearchMovieRequest) (resp model.SearchMovieResponse, err error) {
+	ctx, span := tc.tracer.Start(ctx, "client.SearchMovies", trace.WithAttributes(
+		attribute.String("movie.query", request.SearchQuery),
+		attribute.String("movie.page", request.Page),
+	))
+	defer func() { tracing.End(span, err) }()
+	return tc.next.SearchMovies(ctx, request)
+}
+
+func (tc tracingClient) GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (resp model.GetMovieDetailsResponse, err error) {
+	ctx, span := tc.tracer.Start(ctx, "client.GetMovieDetails", trace.WithAttributes(
+		attribute.String("movie.id", request.MovieID),
+		attribute.String("movie.title", request.Title),
+	))
+	defer func() { tracing.End(span, err) }()
+	return tc.next.GetMovieDetails(ctx, request)
+}
+
+func (tc tracingClient) GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (resp model.GetMovieDetailsResponse, err error) {
+	ctx, span := tc.tracer.Start(ctx, "client.GetMovieDetailsById", trace.WithAttributes(
+		attribute.String("movie.id", request.MovieID),
+	))
+	defer func() { tracing.End(span, err) }()
+	return tc.next.GetMovieDetailsById(ctx, request)
+}
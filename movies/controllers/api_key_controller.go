@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/repository"
+	"go-movie-api/movies/service"
+	"go-movie-api/movies/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type apiKeyController struct {
+	apiKeyService service.APIKeyService
+}
+
+type APIKeyController interface {
+	CreateAPIKey(c *gin.Context)
+	ListAPIKeys(c *gin.Context)
+	RevokeAPIKey(c *gin.Context)
+}
+
+func NewAPIKeyController(apiKeyService service.APIKeyService) APIKeyController {
+	return apiKeyController{apiKeyService: apiKeyService}
+}
+
+func (ac apiKeyController) CreateAPIKey(ctx *gin.Context) {
+	var createReq model.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&createReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	resp, err := ac.apiKeyService.CreateAPIKey(ctx.Request.Context(), createReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// ListAPIKeys lists every key, or only a user's with ?userId=.
+func (ac apiKeyController) ListAPIKeys(ctx *gin.Context) {
+	var query model.ListAPIKeysQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	resp, err := ac.apiKeyService.ListAPIKeys(ctx.Request.Context(), query.UserID)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, resp)
+}
+
+func (ac apiKeyController) RevokeAPIKey(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if uuid.Validate(id) != nil {
+		ctx.Error(repository.ErrAPIKeyNotFound)
+		return
+	}
+
+	err := ac.apiKeyService.RevokeAPIKey(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.RevokeAPIKeyResponse{Status: "Success"})
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/service"
+	"go-movie-api/movies/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+type authController struct {
+	authService service.AuthService
+}
+
+type AuthController interface {
+	Login(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+}
+
+func NewAuthController(authService service.AuthService) AuthController {
+	return authController{authService: authService}
+}
+
+func (ac authController) Login(ctx *gin.Context) {
+	var loginReq model.LoginRequest
+	if err := ctx.ShouldBindJSON(&loginReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	resp, err := ac.authService.Login(ctx.Request.Context(), loginReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, resp)
+}
+
+func (ac authController) Refresh(ctx *gin.Context) {
+	var refreshReq model.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&refreshReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	resp, err := ac.authService.Refresh(ctx.Request.Context(), refreshReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, resp)
+}
+
+func (ac authController) Logout(ctx *gin.Context) {
+	var logoutReq model.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&logoutReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	err := ac.authService.Logout(ctx.Request.Context(), logoutReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.LogoutResponse{Status: "Success"})
+}
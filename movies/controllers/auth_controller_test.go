@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/middleware"
+	mock_service "go-movie-api/movies/mock"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/service"
+	"go-movie-api/movies/validation"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func setupAuthRouter(ctrl *gomock.Controller) (*gin.Engine, *mock_service.MockAuthService) {
+	mockService := mock_service.NewMockAuthService(ctrl)
+	controller := NewAuthController(mockService)
+
+	gin.SetMode(gin.TestMode)
+	if err := validation.Register(); err != nil {
+		panic(err)
+	}
+	r := gin.New()
+	r.Use(middleware.Errors())
+
+	r.POST("/auth/login", controller.Login)
+	r.POST("/auth/refresh", controller.Refresh)
+	r.POST("/auth/logout", controller.Logout)
+
+	return r, mockService
+}
+
+func TestLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupAuthRouter(ctrl)
+
+	t.Run("should return tokens for valid credentials", func(t *testing.T) {
+		reqBody := model.LoginRequest{Email: "jane@example.com", Password: "correct horse"}
+		expected := model.TokenResponse{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 900}
+
+		mockService.EXPECT().Login(gomock.Any(), reqBody).Return(expected, nil)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var got model.TokenResponse
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("should return unauthorized for invalid credentials", func(t *testing.T) {
+		reqBody := model.LoginRequest{Email: "jane@example.com", Password: "wrong horse"}
+
+		mockService.EXPECT().Login(gomock.Any(), reqBody).Return(model.TokenResponse{}, service.ErrInvalidCredentials)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "Bearer", resp.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("should return bad request when the password is missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"jane@example.com"}`))
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestRefreshAndLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupAuthRouter(ctrl)
+	reqBody := model.RefreshTokenRequest{RefreshToken: "refresh"}
+	body, _ := json.Marshal(reqBody)
+
+	t.Run("should return unauthorized for a used refresh token", func(t *testing.T) {
+		mockService.EXPECT().Refresh(gomock.Any(), reqBody).Return(model.TokenResponse{}, auth.ErrInvalidToken)
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(body))
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("should log out", func(t *testing.T) {
+		mockService.EXPECT().Logout(gomock.Any(), reqBody).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewBuffer(body))
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{"status":"Success"}`, resp.Body.String())
+	})
+}
@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"go-movie-api/movies/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+type docsController struct {
+	spec *openapi.Document
+}
+
+type DocsController interface {
+	GetSpec(c *gin.Context)
+	GetSwaggerUI(c *gin.Context)
+}
+
+func NewDocsController(spec *openapi.Document) DocsController {
+	return docsController{spec: spec}
+}
+
+func (dc docsController) GetSpec(ctx *gin.Context) {
+	ctx.JSON(200, dc.spec)
+}
+
+func (dc docsController) GetSwaggerUI(ctx *gin.Context) {
+	ctx.Data(200, "text/html; charset=utf-8", openapi.SwaggerUI)
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/model"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// HealthCheck checks one dependency. The service isn't ready while a
+// critical check fails; other failures only mark it degraded. /readyz is
+// public, so a failure only shows the detail of an *apperror.Error; other
+// errors are logged and shown as down.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) (details map[string]any, err error)
+}
+
+type healthController struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+type HealthController interface {
+	Liveness(c *gin.Context)
+	Readiness(c *gin.Context)
+}
+
+func NewHealthController(logger *slog.Logger, checks ...HealthCheck) HealthController {
+	return healthController{checks: checks, timeout: defaultHealthCheckTimeout, logger: logger}
+}
+
+// Liveness only tells that the process is up and serving requests.
+func (hc healthController) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, model.HealthReport{Status: model.HealthOK})
+}
+
+// Readiness runs every check concurrently and answers 503 when a critical
+// one fails.
+func (hc healthController) Readiness(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), hc.timeout)
+	defer cancel()
+
+	results := make([]model.DependencyHealth, len(hc.checks))
+	var wg sync.WaitGroup
+	for i, check := range hc.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = hc.run(checkCtx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := model.HealthReport{Status: model.HealthOK, Checks: make(map[string]model.DependencyHealth, len(hc.checks))}
+	for i, check := range hc.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		switch {
+		case result.Status == model.HealthOK:
+		case check.Critical:
+			report.Status = model.HealthUnavailable
+		case report.Status == model.HealthOK:
+			report.Status = model.HealthDegraded
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status == model.HealthUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
+
+func (hc healthController) run(ctx context.Context, check HealthCheck) model.DependencyHealth {
+	start := time.Now()
+	details, err := check.Check(ctx)
+
+	result := model.DependencyHealth{
+		Status:    model.HealthOK,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		hc.logger.WarnContext(ctx, "health check failed", "check", check.Name, "error", err)
+		result.Status = model.HealthUnavailable
+		result.Error = healthCheckError(err)
+	}
+	return result
+}
+
+// healthCheckError is what /readyz shows of a failed check.
+func healthCheckError(err error) string {
+	var appErr *apperror.Error
+	switch {
+	case errors.As(err, &appErr):
+		return appErr.Detail
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	default:
+		return "down"
+	}
+}
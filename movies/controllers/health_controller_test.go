@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func passingCheck(name string, critical bool) HealthCheck {
+	return HealthCheck{Name: name, Critical: critical, Check: func(ctx context.Context) (map[string]any, error) {
+		return map[string]any{"version": 6}, nil
+	}}
+}
+
+func failingCheck(name string, critical bool) HealthCheck {
+	return HealthCheck{Name: name, Critical: critical, Check: func(ctx context.Context) (map[string]any, error) {
+		return nil, errors.New("connection refused")
+	}}
+}
+
+func getReadiness(t *testing.T, controller HealthController) (int, model.HealthReport) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/readyz", controller.Readiness)
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report model.HealthReport
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &report))
+	return resp.Code, report
+}
+
+func TestLiveness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/healthz", NewHealthController(logging.Discard(), failingCheck("database", true)).Liveness)
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, resp.Body.String())
+}
+
+func TestReadiness(t *testing.T) {
+	t.Run("should be ready when every check passes", func(t *testing.T) {
+		status, report := getReadiness(t, NewHealthController(logging.Discard(), passingCheck("database", true), passingCheck("movieProvider", false)))
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, model.HealthOK, report.Status)
+		assert.Equal(t, model.HealthOK, report.Checks["database"].Status)
+		assert.Equal(t, map[string]any{"version": float64(6)}, report.Checks["database"].Details)
+		assert.True(t, report.Checks["database"].Critical)
+	})
+
+	t.Run("should be unavailable when a critical check fails", func(t *testing.T) {
+		status, report := getReadiness(t, NewHealthController(logging.Discard(), failingCheck("database", true), passingCheck("movieProvider", false)))
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, model.HealthUnavailable, report.Status)
+		assert.Equal(t, model.HealthUnavailable, report.Checks["database"].Status)
+		assert.Equal(t, "down", report.Checks["database"].Error)
+	})
+
+	t.Run("should show the detail of an app error", func(t *testing.T) {
+		pending := HealthCheck{Name: "migrations", Critical: true, Check: func(ctx context.Context) (map[string]any, error) {
+			return nil, apperror.Unavailable("migrations_pending", "database is at version 4, expected 10")
+		}}
+
+		_, report := getReadiness(t, NewHealthController(logging.Discard(), pending))
+
+		assert.Equal(t, "database is at version 4, expected 10", report.Checks["migrations"].Error)
+	})
+
+	t.Run("should be degraded when only a non-critical check fails", func(t *testing.T) {
+		status, report := getReadiness(t, NewHealthController(logging.Discard(), passingCheck("database", true), failingCheck("movieProvider", false)))
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, model.HealthDegraded, report.Status)
+		assert.Equal(t, model.HealthUnavailable, report.Checks["movieProvider"].Status)
+	})
+
+	t.Run("should time out slow checks and report their latency", func(t *testing.T) {
+		slow := HealthCheck{Name: "database", Critical: true, Check: func(ctx context.Context) (map[string]any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}}
+		controller := healthController{checks: []HealthCheck{slow}, timeout: 20 * time.Millisecond, logger: logging.Discard()}
+
+		status, report := getReadiness(t, controller)
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "timed out", report.Checks["database"].Error)
+		assert.GreaterOrEqual(t, report.Checks["database"].LatencyMs, float64(20))
+	})
+}
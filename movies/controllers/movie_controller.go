@@ -1,10 +1,10 @@
 package controllers
 
 import (
+	"go-movie-api/movies/auth"
 	"go-movie-api/movies/model"
 	"go-movie-api/movies/service"
-	"log"
-	"net/http"
+	"go-movie-api/movies/validation"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,9 +16,15 @@ type moviesController struct {
 type MoviesController interface {
 	SendMessage(c *gin.Context)
 	SearchMovies(c *gin.Context)
+	ListMovies(c *gin.Context)
 	GetMovieDetails(c *gin.Context)
+	GetMovie(c *gin.Context)
 	AddToMovieCart(c *gin.Context)
 	GetMoviesInCart(c *gin.Context)
+	RemoveFromMovieCart(c *gin.Context)
+	RemoveCartMovie(c *gin.Context)
+	ClearMovieCart(c *gin.Context)
+	ReorderMovieCart(c *gin.Context)
 }
 
 func NewMoviesController(movieService service.MovieService) MoviesController {
@@ -32,16 +38,38 @@ func (mc moviesController) SendMessage(c *gin.Context) {
 func (mc moviesController) SearchMovies(ctx *gin.Context) {
 	var movieReq model.SearchMovieRequest
 	if err := ctx.ShouldBindJSON(&movieReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(validation.Error(err))
 		return
 	}
 
-	log.Println("request is valid")
+	resp, err := mc.movieService.SearchMovies(ctx.Request.Context(), movieReq)
 
-	resp, err := mc.movieService.SearchMovies(ctx, movieReq)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, resp)
+}
+
+// ListMovies is SearchMovies with the search in the query string.
+func (mc moviesController) ListMovies(ctx *gin.Context) {
+	var query model.SearchMoviesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	resp, err := mc.movieService.SearchMovies(ctx.Request.Context(), model.SearchMovieRequest{
+		SearchQuery: query.Query,
+		Title:       query.Title,
+		Type:        query.Type,
+		Year:        query.Year,
+		Page:        query.Page,
+	})
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -51,16 +79,32 @@ func (mc moviesController) SearchMovies(ctx *gin.Context) {
 func (mc moviesController) GetMovieDetails(ctx *gin.Context) {
 	var movieReq model.GetMovieDetailsRequest
 	if err := ctx.ShouldBindJSON(&movieReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(validation.Error(err))
 		return
 	}
 
-	log.Println("request is valid")
+	resp, err := mc.movieService.GetMovieDetails(ctx.Request.Context(), movieReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, resp)
+}
+
+// GetMovie is GetMovieDetails for the movie in the path.
+func (mc moviesController) GetMovie(ctx *gin.Context) {
+	var movie model.MovieURI
+	if err := ctx.ShouldBindUri(&movie); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
 
-	resp, err := mc.movieService.GetMovieDetails(ctx, movieReq)
+	resp, err := mc.movieService.GetMovieDetails(ctx.Request.Context(), model.GetMovieDetailsRequest{MovieID: movie.ImdbID})
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -70,16 +114,20 @@ func (mc moviesController) GetMovieDetails(ctx *gin.Context) {
 func (mc moviesController) AddToMovieCart(ctx *gin.Context) {
 	var addMovieToCartReq model.AddMovieToCartRequest
 	if err := ctx.ShouldBindJSON(&addMovieToCartReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(validation.Error(err))
 		return
 	}
 
-	log.Println("request is valid")
+	userId, ok := cartOwner(ctx)
+	if !ok {
+		return
+	}
+	addMovieToCartReq.UserID = userId
 
-	err := mc.movieService.AddMovieToCart(ctx, addMovieToCartReq)
+	err := mc.movieService.AddMovieToCart(ctx.Request.Context(), addMovieToCartReq)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -87,18 +135,133 @@ func (mc moviesController) AddToMovieCart(ctx *gin.Context) {
 }
 
 func (mc moviesController) GetMoviesInCart(ctx *gin.Context) {
-	var getMoviesInCartReq model.GetMoviesInCartReq
-	if err := ctx.ShouldBindJSON(&getMoviesInCartReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	userId, ok := cartOwner(ctx)
+	if !ok {
 		return
 	}
+	getMoviesInCartReq := model.GetMoviesInCartReq{UserID: userId}
 
-	resp, err := mc.movieService.GetMoviesInCart(ctx, getMoviesInCartReq)
+	resp, err := mc.movieService.GetMoviesInCart(ctx.Request.Context(), getMoviesInCartReq)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(200, resp)
 }
+
+func (mc moviesController) RemoveFromMovieCart(ctx *gin.Context) {
+	var removeMovieReq model.RemoveMovieFromCartRequest
+	if err := ctx.ShouldBindJSON(&removeMovieReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	userId, ok := cartOwner(ctx)
+	if !ok {
+		return
+	}
+	removeMovieReq.UserID = userId
+
+	err := mc.movieService.RemoveMovieFromCart(ctx.Request.Context(), removeMovieReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.UpdateMovieCartResponse{Status: "Success"})
+}
+
+// RemoveCartMovie is RemoveFromMovieCart for the movie in the path.
+func (mc moviesController) RemoveCartMovie(ctx *gin.Context) {
+	var movie model.MovieURI
+	if err := ctx.ShouldBindUri(&movie); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	userId, ok := cartOwner(ctx)
+	if !ok {
+		return
+	}
+	removeMovieReq := model.RemoveMovieFromCartRequest{MovieID: movie.ImdbID, UserID: userId}
+
+	err := mc.movieService.RemoveMovieFromCart(ctx.Request.Context(), removeMovieReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.UpdateMovieCartResponse{Status: "Success"})
+}
+
+func (mc moviesController) ClearMovieCart(ctx *gin.Context) {
+	userId, ok := cartOwner(ctx)
+	if !ok {
+		return
+	}
+	clearCartReq := model.ClearMovieCartRequest{UserID: userId}
+
+	err := mc.movieService.ClearMovieCart(ctx.Request.Context(), clearCartReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.UpdateMovieCartResponse{Status: "Success"})
+}
+
+func (mc moviesController) ReorderMovieCart(ctx *gin.Context) {
+	var reorderCartReq model.ReorderMovieCartRequest
+	if err := ctx.ShouldBindJSON(&reorderCartReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	userId, ok := cartOwner(ctx)
+	if !ok {
+		return
+	}
+	reorderCartReq.UserID = userId
+
+	err := mc.movieService.ReorderMovieCart(ctx.Request.Context(), reorderCartReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.UpdateMovieCartResponse{Status: "Success"})
+}
+
+// cartOwner returns whose cart the request is about: the user in the path,
+// which only admins may set to someone else, or else the authenticated user.
+// It fails the request with a 401 or 403 when it may not touch that cart.
+func cartOwner(ctx *gin.Context) (string, bool) {
+	principal, ok := auth.FromContext(ctx.Request.Context())
+	if !ok {
+		ctx.Error(auth.ErrAuthenticationRequired)
+		return "", false
+	}
+
+	if ctx.Param("userId") == "" {
+		return principal.UserID, true
+	}
+	var uri model.UserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(validation.Error(err))
+		return "", false
+	}
+	if uri.UserID == principal.UserID {
+		return principal.UserID, true
+	}
+	if !principal.IsAdmin() {
+		ctx.Error(auth.ErrForbidden)
+		return "", false
+	}
+	return uri.UserID, true
+}
@@ -4,29 +4,68 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/client"
+	"go-movie-api/movies/middleware"
 	mock_service "go-movie-api/movies/mock"
 	"go-movie-api/movies/model"
+	"go-movie-api/movies/repository"
+	"go-movie-api/movies/validation"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+// testUserHeader and testRoleHeader stand in for the auth middleware:
+// requests that set them are made as that user, with that role.
+const (
+	testUserHeader = "X-Test-User"
+	testRoleHeader = "X-Test-Role"
+)
+
+// ownUserID and otherUserID are users for the routes with a user id in the path.
+const (
+	ownUserID   = "0b7e4c1e-5f6a-4d0b-9a51-3c2f8e1d7a10"
+	otherUserID = "5d2c9b8a-1e3f-4a7b-8c6d-9e0f1a2b3c4d"
+)
+
 func setupRouter(ctrl *gomock.Controller) (*gin.Engine, *mock_service.MockMovieService) {
 	mockService := mock_service.NewMockMovieService(ctrl)
 	controller := NewMoviesController(mockService)
 
 	gin.SetMode(gin.TestMode)
+	if err := validation.Register(); err != nil {
+		panic(err)
+	}
 	r := gin.Default()
+	r.Use(middleware.Errors(), func(c *gin.Context) {
+		if userId := c.GetHeader(testUserHeader); userId != "" {
+			principal := auth.Principal{UserID: userId, Role: auth.RoleUser}
+			if role := c.GetHeader(testRoleHeader); role != "" {
+				principal.Role = role
+			}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+	})
 
 	r.GET("/", controller.SendMessage)
 	r.POST("/search", controller.SearchMovies)
 	r.POST("/details", controller.GetMovieDetails)
 	r.POST("/cart", controller.AddToMovieCart)
 	r.GET("/cart", controller.GetMoviesInCart)
+	r.POST("/cart/remove", controller.RemoveFromMovieCart)
+	r.POST("/cart/clear", controller.ClearMovieCart)
+	r.POST("/cart/reorder", controller.ReorderMovieCart)
+	r.GET("/users/:userId/cart", controller.GetMoviesInCart)
+	r.GET("/movies", controller.ListMovies)
+	r.GET("/movies/:imdbId", controller.GetMovie)
+	r.DELETE("/users/:userId/cart/:imdbId", controller.RemoveCartMovie)
 
 	return r, mockService
 }
@@ -82,6 +121,23 @@ func TestSearchMovies(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
 	})
 
+	t.Run("should list the fields that break the validation rules", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBufferString(`{"searchText":"ab","type":"game"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		var problem model.Problem
+		json.Unmarshal(resp.Body.Bytes(), &problem)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "invalid_request", problem.Code)
+		assert.ElementsMatch(t, []model.FieldError{
+			{Field: "searchText", Rule: "searchtext", Message: "must be at least 3 characters"},
+			{Field: "type", Rule: "movietype", Message: "must be one of movie, series, episode"},
+		}, problem.Errors)
+	})
+
 	t.Run("should return internal server error error when movies end point is failing for any reason", func(t *testing.T) {
 		reqBody := model.SearchMovieRequest{SearchQuery: "Batman"}
 
@@ -97,6 +153,7 @@ func TestSearchMovies(t *testing.T) {
 		router.ServeHTTP(resp, req)
 
 		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+		assert.NotContains(t, resp.Body.String(), "service failed")
 	})
 }
 
@@ -134,6 +191,16 @@ func TestGetMovieDetails(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
 	})
 
+	t.Run("should return bad request without a title or a movie id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/details", bytes.NewBufferString("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "movieId is required when title is not set")
+	})
+
 	t.Run("should return internal server error error when movies end point is failing for any reason", func(t *testing.T) {
 		reqBody := model.GetMovieDetailsRequest{MovieID: "tt1375666"}
 
@@ -166,6 +233,7 @@ func TestAddToMovieCart(t *testing.T) {
 
 		body, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/cart", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
 		req.Header.Set("Content-Type", "application/json")
 		resp := httptest.NewRecorder()
 
@@ -191,6 +259,7 @@ func TestAddToMovieCart(t *testing.T) {
 
 		body, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/cart", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
 		req.Header.Set("Content-Type", "application/json")
 		resp := httptest.NewRecorder()
 
@@ -199,6 +268,50 @@ func TestAddToMovieCart(t *testing.T) {
 	})
 }
 
+// TMDB search hits have no IMDb id, so they carry TMDB ids that the details
+// and cart routes must take back.
+func TestTmdbIdRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+	serve := func(method, path string, body any) *httptest.ResponseRecorder {
+		var reader *bytes.Buffer
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(encoded)
+		} else {
+			reader = &bytes.Buffer{}
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(testUserHeader, "123")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	mockService.EXPECT().
+		SearchMovies(gomock.Any(), model.SearchMovieRequest{SearchQuery: "Inception"}).
+		Return([]model.Movie{{Title: "Inception", Year: "2010", ImdbID: "tmdb:movie:27205"}}, nil)
+	resp := serve(http.MethodPost, "/search", model.SearchMovieRequest{SearchQuery: "Inception"})
+	var movies []model.Movie
+	json.Unmarshal(resp.Body.Bytes(), &movies)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	id := movies[0].ImdbID
+
+	mockService.EXPECT().
+		GetMovieDetails(gomock.Any(), model.GetMovieDetailsRequest{MovieID: id}).
+		Return(model.GetMovieDetailsResponse{Title: "Inception"}, nil).Times(2)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/details", model.GetMovieDetailsRequest{MovieID: id}).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/movies/"+id, nil).Code)
+
+	mockService.EXPECT().
+		AddMovieToCart(gomock.Any(), model.AddMovieToCartRequest{MovieID: id, UserID: "123"}).
+		Return(nil)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/cart", model.AddMovieToCartRequest{MovieID: id}).Code)
+}
+
 func TestGetMoviesInCart(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -207,7 +320,6 @@ func TestGetMoviesInCart(t *testing.T) {
 
 	t.Run("should return the movies details in the cart", func(t *testing.T) {
 		reqBody := model.GetMoviesInCartReq{UserID: "123"}
-		body, _ := json.Marshal(reqBody)
 
 		expected := []model.MovieDetailsInCart{
 			{Title: "Interstellar", ImdbID: "tt0816692"},
@@ -217,7 +329,8 @@ func TestGetMoviesInCart(t *testing.T) {
 			GetMoviesInCart(gomock.Any(), reqBody).
 			Return(expected, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/cart", bytes.NewBuffer(body))
+		req := httptest.NewRequest(http.MethodGet, "/cart", nil)
+		req.Header.Set(testUserHeader, "123")
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -226,15 +339,395 @@ func TestGetMoviesInCart(t *testing.T) {
 
 	t.Run("should return internal server error error when movies end point is failing for any reason", func(t *testing.T) {
 		reqBody := model.GetMoviesInCartReq{UserID: "123"}
-		body, _ := json.Marshal(reqBody)
 		mockService.EXPECT().
 			GetMoviesInCart(gomock.Any(), reqBody).
 			Return(nil, errors.New("db failure"))
 
-		req := httptest.NewRequest(http.MethodGet, "/cart", bytes.NewBuffer(body))
+		req := httptest.NewRequest(http.MethodGet, "/cart", nil)
+		req.Header.Set(testUserHeader, "123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	})
+
+	t.Run("should return unauthorized when there is no user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/cart", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
+
+func TestOtherUsersCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	t.Run("should let an admin see another user's cart", func(t *testing.T) {
+		mockService.EXPECT().
+			GetMoviesInCart(gomock.Any(), model.GetMoviesInCartReq{UserID: otherUserID}).
+			Return([]model.MovieDetailsInCart{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/users/"+otherUserID+"/cart", nil)
+		req.Header.Set(testUserHeader, ownUserID)
+		req.Header.Set(testRoleHeader, auth.RoleAdmin)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("should let a user see their own cart by id", func(t *testing.T) {
+		mockService.EXPECT().
+			GetMoviesInCart(gomock.Any(), model.GetMoviesInCartReq{UserID: ownUserID}).
+			Return([]model.MovieDetailsInCart{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/users/"+ownUserID+"/cart", nil)
+		req.Header.Set(testUserHeader, ownUserID)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("should forbid a user from seeing another user's cart", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/"+otherUserID+"/cart", nil)
+		req.Header.Set(testUserHeader, ownUserID)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("should reject a user id that isn't a uuid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/456/cart", nil)
+		req.Header.Set(testUserHeader, ownUserID)
+		req.Header.Set(testRoleHeader, auth.RoleAdmin)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		var problem model.Problem
+		json.Unmarshal(resp.Body.Bytes(), &problem)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, []model.FieldError{{Field: "userId", Rule: "uuid", Message: "must be a uuid"}}, problem.Errors)
+	})
+}
+
+func TestRemoveFromMovieCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	t.Run("should remove the movie from the cart", func(t *testing.T) {
+		reqBody := model.RemoveMovieFromCartRequest{MovieID: "tt1375666", UserID: "123"}
+
+		mockService.EXPECT().
+			RemoveMovieFromCart(gomock.Any(), reqBody).
+			Return(nil)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/remove", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("should return bad request error when movie id is missing", func(t *testing.T) {
+		body, _ := json.Marshal(model.RemoveMovieFromCartRequest{UserID: "123"})
+		req := httptest.NewRequest(http.MethodPost, "/cart/remove", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("should return not found when the movie is not in the cart", func(t *testing.T) {
+		reqBody := model.RemoveMovieFromCartRequest{MovieID: "tt0000001", UserID: "123"}
+
+		mockService.EXPECT().
+			RemoveMovieFromCart(gomock.Any(), reqBody).
+			Return(repository.ErrMovieNotInCart)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/remove", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("should return internal server error when removing fails", func(t *testing.T) {
+		reqBody := model.RemoveMovieFromCartRequest{MovieID: "tt1375666", UserID: "456"}
+
+		mockService.EXPECT().
+			RemoveMovieFromCart(gomock.Any(), reqBody).
+			Return(errors.New("db failure"))
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/remove", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "456")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	})
+}
+
+func TestClearMovieCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	t.Run("should clear the cart", func(t *testing.T) {
+		reqBody := model.ClearMovieCartRequest{UserID: "123"}
+
+		mockService.EXPECT().
+			ClearMovieCart(gomock.Any(), reqBody).
+			Return(nil)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/clear", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("should return unauthorized when there is no user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/cart/clear", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "Bearer", resp.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("should return internal server error when clearing fails", func(t *testing.T) {
+		reqBody := model.ClearMovieCartRequest{UserID: "456"}
+
+		mockService.EXPECT().
+			ClearMovieCart(gomock.Any(), reqBody).
+			Return(errors.New("db failure"))
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/clear", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "456")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	})
+}
+
+func TestReorderMovieCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	t.Run("should reorder the cart", func(t *testing.T) {
+		reqBody := model.ReorderMovieCartRequest{UserID: "123", MovieIDs: []string{"tt0816692", "tt1375666"}}
+
+		mockService.EXPECT().
+			ReorderMovieCart(gomock.Any(), reqBody).
+			Return(nil)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/reorder", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("should return bad request error when the order does not match the cart", func(t *testing.T) {
+		reqBody := model.ReorderMovieCartRequest{UserID: "123", MovieIDs: []string{"tt1375666"}}
+
+		mockService.EXPECT().
+			ReorderMovieCart(gomock.Any(), reqBody).
+			Return(repository.ErrInvalidCartOrder)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/reorder", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "123")
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("should return internal server error when reordering fails", func(t *testing.T) {
+		reqBody := model.ReorderMovieCartRequest{UserID: "456", MovieIDs: []string{"tt1375666"}}
+
+		mockService.EXPECT().
+			ReorderMovieCart(gomock.Any(), reqBody).
+			Return(errors.New("db failure"))
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/cart/reorder", bytes.NewBuffer(body))
+		req.Header.Set(testUserHeader, "456")
+		req.Header.Set("Content-Type", "application/json")
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
 		assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	})
 }
+
+func TestUpstreamErrorStatuses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	cases := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		retryAfter string
+	}{
+		{"not found", &client.UpstreamError{Kind: client.ErrNotFound}, http.StatusNotFound, "movie_not_found", ""},
+		{"rate limited", &client.UpstreamError{Kind: client.ErrRateLimited, RetryAfter: 1500 * time.Millisecond}, http.StatusTooManyRequests, "provider_rate_limited", "2"},
+		{"upstream unavailable", &client.UpstreamError{Kind: client.ErrUpstreamUnavailable, StatusCode: 503}, http.StatusServiceUnavailable, "provider_unavailable", ""},
+		{"open circuit", &client.UpstreamError{Kind: client.ErrUpstreamUnavailable, RetryAfter: 30 * time.Second, Err: client.ErrCircuitOpen}, http.StatusServiceUnavailable, "provider_unavailable", "30"},
+		{"malformed response", &client.UpstreamError{Kind: client.ErrMalformedResponse}, http.StatusBadGateway, "provider_bad_response", ""},
+		{"already in cart", repository.ErrMovieAlreadyInCart, http.StatusConflict, "movie_already_in_cart", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run("should return "+http.StatusText(tc.status)+" for "+tc.name, func(t *testing.T) {
+			reqBody := model.SearchMovieRequest{SearchQuery: "Batman"}
+
+			mockService.EXPECT().
+				SearchMovies(gomock.Any(), reqBody).
+				Return(nil, tc.err)
+
+			body, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.status, resp.Code)
+			assert.Equal(t, tc.retryAfter, resp.Header().Get("Retry-After"))
+			var problem model.Problem
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+			assert.Equal(t, tc.code, problem.Code)
+		})
+	}
+}
+
+func TestListMovies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	t.Run("should search with the query string", func(t *testing.T) {
+		mockService.EXPECT().
+			SearchMovies(gomock.Any(), model.SearchMovieRequest{SearchQuery: "Batman", Type: "movie", Year: "1989", Page: "2"}).
+			Return([]model.Movie{{Title: "Batman", ImdbID: "tt0096895"}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/movies?q=Batman&type=movie&year=1989&page=2", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), "tt0096895")
+	})
+
+	for _, query := range []string{"", "?type=movie", "?q=Batman&type=film", "?q=Batman&year=89", "?q=Batman&page=two"} {
+		t.Run("should reject the query "+strconv.Quote(query), func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/movies"+query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		})
+	}
+}
+
+func TestGetMovie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	t.Run("should look up the movie in the path", func(t *testing.T) {
+		mockService.EXPECT().
+			GetMovieDetails(gomock.Any(), model.GetMovieDetailsRequest{MovieID: "tt0096895"}).
+			Return(model.GetMovieDetailsResponse{Title: "Batman", ImdbID: "tt0096895"}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/movies/tt0096895", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Batman")
+	})
+
+	t.Run("should reject an id that isn't an imdb id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/movies/0096895", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestRemoveCartMovie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, mockService := setupRouter(ctrl)
+
+	t.Run("should remove the movie in the path from the user's cart", func(t *testing.T) {
+		mockService.EXPECT().
+			RemoveMovieFromCart(gomock.Any(), model.RemoveMovieFromCartRequest{MovieID: "tt0096895", UserID: otherUserID}).
+			Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/users/"+otherUserID+"/cart/tt0096895", nil)
+		req.Header.Set(testUserHeader, otherUserID)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("should not let users change another user's cart", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/users/"+ownUserID+"/cart/tt0096895", nil)
+		req.Header.Set(testUserHeader, otherUserID)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"go-movie-api/movies/client"
+
+	"github.com/gin-gonic/gin"
+)
+
+type providerController struct {
+	breaker    *client.CircuitBreaker
+	cache      *client.CachingClient
+	coalescing *client.CoalescingClient
+}
+
+type ProviderController interface {
+	GetCircuitStatus(c *gin.Context)
+	GetCacheStats(c *gin.Context)
+	GetCoalescingStats(c *gin.Context)
+}
+
+func NewProviderController(breaker *client.CircuitBreaker, cache *client.CachingClient, coalescing *client.CoalescingClient) ProviderController {
+	return providerController{breaker: breaker, cache: cache, coalescing: coalescing}
+}
+
+func (pc providerController) GetCircuitStatus(ctx *gin.Context) {
+	ctx.JSON(200, pc.breaker.Status())
+}
+
+func (pc providerController) GetCacheStats(ctx *gin.Context) {
+	ctx.JSON(200, pc.cache.Stats())
+}
+
+func (pc providerController) GetCoalescingStats(ctx *gin.Context) {
+	ctx.JSON(200, pc.coalescing.Stats())
+}
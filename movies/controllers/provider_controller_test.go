@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"encoding/json"
+	"go-movie-api/configs"
+	"go-movie-api/movies/client"
+	"go-movie-api/movies/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCircuitStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	breaker := client.NewCircuitBreaker(configs.BreakerConfig{FailureThreshold: 1, CoolDownMs: 60000}, logging.Discard())
+	controller := NewProviderController(breaker, nil, nil)
+
+	r := gin.New()
+	r.GET("/status/provider", controller.GetCircuitStatus)
+
+	t.Run("should report a closed circuit", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/status/provider", nil))
+
+		var status client.BreakerStatus
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &status))
+		assert.Equal(t, client.CircuitClosed, status.State)
+	})
+
+	t.Run("should report an open circuit with retry after", func(t *testing.T) {
+		assert.NoError(t, breaker.Allow())
+		breaker.Done(&client.UpstreamError{Kind: client.ErrUpstreamUnavailable, StatusCode: 502})
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/status/provider", nil))
+
+		var status client.BreakerStatus
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &status))
+		assert.Equal(t, client.CircuitOpen, status.State)
+		assert.NotNil(t, status.OpenedAt)
+		assert.Greater(t, status.RetryAfterSeconds, 0)
+	})
+}
+
+func TestGetCacheStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cache := client.NewCachingClient(nil, client.NewMemoryCacheStore(10), configs.CacheConfig{}, logging.Discard())
+	controller := NewProviderController(nil, cache, nil)
+
+	r := gin.New()
+	r.GET("/status/cache", controller.GetCacheStats)
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/status/cache", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"hits":0,"misses":0,"negativeHits":0}`, resp.Body.String())
+}
+
+func TestGetCoalescingStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := NewProviderController(nil, nil, client.NewCoalescingClient(nil))
+
+	r := gin.New()
+	r.GET("/status/coalescing", controller.GetCoalescingStats)
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/status/coalescing", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"calls":0,"deduplicated":0}`, resp.Body.String())
+}
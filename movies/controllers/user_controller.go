@@ -1,10 +1,11 @@
 package controllers
 
 import (
+	"go-movie-api/movies/auth"
 	"go-movie-api/movies/model"
 	"go-movie-api/movies/service"
-	"log"
-	"net/http"
+	"go-movie-api/movies/validation"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,9 +16,19 @@ type userController struct {
 
 type UserController interface {
 	CreateUser(c *gin.Context)
-	GetUsers(c *gin.Context)
+	ListUsers(c *gin.Context)
+	LookupUser(c *gin.Context)
+	GetUser(c *gin.Context)
+	UpdateUser(c *gin.Context)
+	DeleteUser(c *gin.Context)
+	RestoreUser(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
 }
 
+// totalCountHeader carries how many users a listing matched in all.
+const totalCountHeader = "X-Total-Count"
+
 func NewUserController(userService service.UserService) UserController {
 	return userController{userService: userService}
 }
@@ -25,29 +36,205 @@ func NewUserController(userService service.UserService) UserController {
 func (mc userController) CreateUser(ctx *gin.Context) {
 	var createUserReq model.CreateUserRequest
 	if err := ctx.ShouldBindJSON(&createUserReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(validation.Error(err))
 		return
 	}
 
-	log.Println("request is valid")
-
-	err := mc.userService.CreateUser(createUserReq)
+	err := mc.userService.CreateUser(ctx.Request.Context(), createUserReq)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(200, model.CreateUserResponse{Status: "Success"})
 }
 
-func (mc userController) GetUsers(ctx *gin.Context) {
-	resp, err := mc.userService.GetUsers()
+// ListUsers answers with a page of users and their total count in the
+// X-Total-Count header.
+func (mc userController) ListUsers(ctx *gin.Context) {
+	var query model.ListUsersQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	users, total, err := mc.userService.ListUsers(ctx.Request.Context(), query)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header(totalCountHeader, strconv.Itoa(total))
+	ctx.JSON(200, users)
+}
+
+func (mc userController) LookupUser(ctx *gin.Context) {
+	var query model.LookupUserQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	user, err := mc.userService.GetUserByEmail(ctx.Request.Context(), query.Email)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, user)
+}
+
+func (mc userController) GetUser(ctx *gin.Context) {
+	userId, ok := accountOwner(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := mc.userService.GetUser(ctx.Request.Context(), userId)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, user)
+}
+
+func (mc userController) UpdateUser(ctx *gin.Context) {
+	userId, ok := accountOwner(ctx)
+	if !ok {
+		return
+	}
+
+	var updateUserReq model.UpdateUserRequest
+	if err := ctx.ShouldBindJSON(&updateUserReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	user, err := mc.userService.UpdateUser(ctx.Request.Context(), userId, updateUserReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, user)
+}
+
+func (mc userController) DeleteUser(ctx *gin.Context) {
+	userId, ok := accountOwner(ctx)
+	if !ok {
+		return
+	}
+
+	err := mc.userService.DeleteUser(ctx.Request.Context(), userId)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, resp)
+	ctx.JSON(200, model.DeleteUserResponse{Status: "Success"})
+}
+
+func (mc userController) RestoreUser(ctx *gin.Context) {
+	var uri model.UserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	user, err := mc.userService.RestoreUser(ctx.Request.Context(), uri.UserID)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, user)
+}
+
+// ChangePassword is only for the user themselves, signed in with a token:
+// admins reset passwords instead, and an API key must not be able to take
+// over the account it acts for.
+func (mc userController) ChangePassword(ctx *gin.Context) {
+	var uri model.UserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	principal, ok := auth.FromContext(ctx.Request.Context())
+	if !ok {
+		ctx.Error(auth.ErrAuthenticationRequired)
+		return
+	}
+	if uri.UserID != principal.UserID || principal.APIKeyID != "" {
+		ctx.Error(auth.ErrForbidden)
+		return
+	}
+
+	var changePasswordReq model.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&changePasswordReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	err := mc.userService.ChangePassword(ctx.Request.Context(), uri.UserID, changePasswordReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.PasswordResponse{Status: "Success"})
+}
+
+func (mc userController) ResetPassword(ctx *gin.Context) {
+	var uri model.UserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	var resetPasswordReq model.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&resetPasswordReq); err != nil {
+		ctx.Error(validation.Error(err))
+		return
+	}
+
+	err := mc.userService.ResetPassword(ctx.Request.Context(), uri.UserID, resetPasswordReq)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, model.PasswordResponse{Status: "Success"})
+}
+
+// accountOwner returns the user in the path when the request may manage
+// them: they are the authenticated user, or the principal is an admin.
+// Otherwise it fails the request.
+func accountOwner(ctx *gin.Context) (string, bool) {
+	var uri model.UserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(validation.Error(err))
+		return "", false
+	}
+
+	principal, ok := auth.FromContext(ctx.Request.Context())
+	if !ok {
+		ctx.Error(auth.ErrAuthenticationRequired)
+		return "", false
+	}
+	if uri.UserID != principal.UserID && !principal.IsAdmin() {
+		ctx.Error(auth.ErrForbidden)
+		return "", false
+	}
+	return uri.UserID, true
 }
@@ -1,25 +1,60 @@
 package database
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"go-movie-api/configs"
+	"log/slog"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
-var db *sqlx.DB
-
-func InitDB() *sqlx.DB {
+// InitDB opens a connection pool with the given settings. The database often
+// starts alongside the app, so the first connection is retried with
+// exponential backoff before giving up.
+func InitDB(ctx context.Context, cfg configs.DatabaseConfig, logger *slog.Logger) (*sqlx.DB, error) {
+	db, err := sqlx.Open("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("invalid database settings: %w", err)
+	}
 
-	dbConnectStr := "user=postgres password=sanjeev dbname=postgres host=localhost port=5432 sslmode=disable"
-	var err error
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime())
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime())
 
-	db, err = sqlx.Connect("postgres", dbConnectStr)
-	if err != nil {
-		log.Fatalf("Database connection failed: %v", err)
-		panic(err)
+	if err := connect(ctx, db, cfg, logger); err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	log.Println("Connected to PostgreSQL via sqlx.")
-	return db
+	logger.Info("connected to the database", "host", cfg.Host, "port", cfg.Port, "name", cfg.Name)
+	return db, nil
+}
+
+func connect(ctx context.Context, db *sqlx.DB, cfg configs.DatabaseConfig, logger *slog.Logger) error {
+	delay := cfg.RetryBaseDelay()
+	for attempt := 0; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return fmt.Errorf("database connection failed after %d attempts: %w", attempt+1, err)
+		}
+
+		logger.Warn("database connection failed, retrying", "attempt", attempt+1, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("database connection failed: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, cfg.RetryMaxDelay())
+	}
 }
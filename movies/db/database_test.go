@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// closedPort returns a local port nothing is listening on.
+func closedPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
+
+func unreachableConfig(t *testing.T) configs.DatabaseConfig {
+	cfg := configs.NewConfig().GetDatabaseConfig()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = closedPort(t)
+	cfg.RetryBaseDelayMs = 1
+	cfg.RetryMaxDelayMs = 2
+	return cfg
+}
+
+func TestInitDBRetriesThenFails(t *testing.T) {
+	cfg := unreachableConfig(t)
+	cfg.ConnectRetries = 2
+
+	db, err := InitDB(context.Background(), cfg, logging.Discard())
+
+	assert.Nil(t, db)
+	assert.ErrorContains(t, err, "database connection failed after 3 attempts")
+}
+
+func TestInitDBStopsRetryingWhenCancelled(t *testing.T) {
+	cfg := unreachableConfig(t)
+	cfg.ConnectRetries = 100
+	cfg.RetryBaseDelayMs = 1000
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := InitDB(ctx, cfg, logging.Discard())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,323 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationLockId is the advisory lock held while migrating, so instances
+// starting together don't run the same migration twice.
+const migrationLockId = 724_617_345
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a pair of SQL scripts named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. The checksum covers the up script, which must
+// not change once it has been applied.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type appliedMigration struct {
+	Version  int    `db:"version"`
+	Name     string `db:"name"`
+	Checksum string `db:"checksum"`
+}
+
+// Migrator applies the migrations embedded in the binary and records them in
+// the schema_migrations table.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+func NewMigrator(db *sqlx.DB, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(db, migrations, logger)
+}
+
+func newMigrator(db *sqlx.DB, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, logger: logger}, nil
+}
+
+// Latest is the version the database is at once every migration is applied.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version is the newest migration applied to the database.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	err := m.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "failed to read the migration version", "error", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+// Up applies every migration that hasn't been applied yet, in order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			m.logger.InfoContext(ctx, "applying migration", "version", migration.Version, "name", migration.Name)
+			err := inTx(ctx, conn, func(tx *sqlx.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					migration.Version, migration.Name, migration.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the newest steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s can't be reverted", migration.Version, migration.Name)
+			}
+
+			m.logger.InfoContext(ctx, "reverting migration", "version", migration.Version, "name", migration.Name)
+			err := inTx(ctx, conn, func(tx *sqlx.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// withLock runs fn on a single connection holding the migration lock.
+// Session advisory locks belong to a connection, so everything has to run
+// on the one that took it.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "failed to get a connection for migrating", "error", err)
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockId); err != nil {
+		m.logger.ErrorContext(ctx, "failed to take the migration lock", "error", err)
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockId); err != nil {
+			m.logger.ErrorContext(ctx, "failed to release the migration lock", "error", err)
+		}
+	}()
+
+	return fn(conn)
+}
+
+// applied returns the migrations recorded in the database, after checking
+// that they match the ones in the binary.
+func (m *Migrator) applied(ctx context.Context, conn *sqlx.Conn) (map[int]appliedMigration, error) {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint NOT NULL PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		checksum varchar(64) NOT NULL,
+		applied_at timestamptz DEFAULT NOW() NOT NULL
+	)`)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "failed to create schema_migrations", "error", err)
+		return nil, err
+	}
+
+	var rows []appliedMigration
+	if err := conn.SelectContext(ctx, &rows, `SELECT version, name, checksum FROM schema_migrations ORDER BY version`); err != nil {
+		m.logger.ErrorContext(ctx, "failed to read applied migrations", "error", err)
+		return nil, err
+	}
+	if len(rows) == 0 {
+		if rows, err = m.adoptLiquibase(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	applied := make(map[int]appliedMigration, len(rows))
+	for _, row := range rows {
+		migration, ok := known[row.Version]
+		if !ok {
+			return nil, fmt.Errorf("database has migration %d_%s, which this build doesn't know about", row.Version, row.Name)
+		}
+		if row.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %d_%s has changed since it was applied", row.Version, row.Name)
+		}
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// adoptLiquibase records the changeSets of a database migrated with the old
+// Liquibase changelog as applied. Its changeSet ids match the versions of
+// the migrations they were ported to.
+func (m *Migrator) adoptLiquibase(ctx context.Context, conn *sqlx.Conn) ([]appliedMigration, error) {
+	var exists bool
+	if err := conn.QueryRowxContext(ctx, `SELECT to_regclass('databasechangelog') IS NOT NULL`).Scan(&exists); err != nil {
+		m.logger.ErrorContext(ctx, "failed to look for a liquibase changelog", "error", err)
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var ids []string
+	if err := conn.SelectContext(ctx, &ids, `SELECT id FROM databasechangelog`); err != nil {
+		m.logger.ErrorContext(ctx, "failed to read the liquibase changelog", "error", err)
+		return nil, err
+	}
+
+	changeSets := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if version, err := strconv.Atoi(id); err == nil {
+			changeSets[version] = true
+		}
+	}
+
+	var adopted []appliedMigration
+	for _, migration := range m.migrations {
+		if !changeSets[migration.Version] {
+			continue
+		}
+		_, err := conn.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, migration.Checksum)
+		if err != nil {
+			m.logger.ErrorContext(ctx, "failed to record an adopted migration", "error", err)
+			return nil, err
+		}
+		adopted = append(adopted, appliedMigration{Version: migration.Version, Name: migration.Name, Checksum: migration.Checksum})
+	}
+
+	m.logger.InfoContext(ctx, "adopted migrations applied by liquibase", "count", len(adopted))
+	return adopted, nil
+}
+
+func inTx(ctx context.Context, conn *sqlx.Conn, fn func(tx *sqlx.Tx) error) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		parts := migrationFileName.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(parts[1])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = migration
+		} else if migration.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, parts[2])
+		}
+
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if parts[3] == "up" {
+			migration.Up = string(script)
+			sum := sha256.Sum256(script)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
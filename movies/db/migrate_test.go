@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/logging"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = fstest.MapFS{
+	"0001_create_users.up.sql":     {Data: []byte("CREATE TABLE users (id uuid);")},
+	"0001_create_users.down.sql":   {Data: []byte("DROP TABLE users;")},
+	"0002_create_cart.up.sql":      {Data: []byte("CREATE TABLE cart (id uuid);")},
+	"0002_create_cart.down.sql":    {Data: []byte("DROP TABLE cart;")},
+	"0010_add_cart_title.up.sql":   {Data: []byte("ALTER TABLE cart ADD COLUMN title text;")},
+	"0010_add_cart_title.down.sql": {Data: []byte("ALTER TABLE cart DROP COLUMN title;")},
+}
+
+var (
+	lockQuery      = regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)
+	unlockQuery    = regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)
+	createTable    = regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)
+	selectApplied  = regexp.QuoteMeta(`SELECT version, name, checksum FROM schema_migrations ORDER BY version`)
+	liquibaseCheck = regexp.QuoteMeta(`SELECT to_regclass('databasechangelog') IS NOT NULL`)
+	insertApplied  = regexp.QuoteMeta(`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`)
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	mockDb, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { mockDb.Close() })
+
+	migrator, err := newMigrator(sqlx.NewDb(mockDb, "sqlmock"), testMigrations, logging.Discard())
+	assert.NoError(t, err)
+	return migrator, mock
+}
+
+func appliedRows(migrations ...Migration) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum"})
+	for _, migration := range migrations {
+		rows.AddRow(migration.Version, migration.Name, migration.Checksum)
+	}
+	return rows
+}
+
+func expectLocked(mock sqlmock.Sqlmock, applied *sqlmock.Rows) {
+	mock.ExpectExec(lockQuery).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(selectApplied).WillReturnRows(applied)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("should order migrations by version", func(t *testing.T) {
+		migrations, err := loadMigrations(testMigrations)
+
+		assert.NoError(t, err)
+		assert.Len(t, migrations, 3)
+		assert.Equal(t, []int{1, 2, 10}, []int{migrations[0].Version, migrations[1].Version, migrations[2].Version})
+		assert.Equal(t, "add_cart_title", migrations[2].Name)
+		assert.Equal(t, "DROP TABLE users;", migrations[0].Down)
+		assert.Len(t, migrations[0].Checksum, 64)
+	})
+
+	t.Run("should reject a migration without an up script", func(t *testing.T) {
+		_, err := loadMigrations(fstest.MapFS{"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")}})
+
+		assert.EqualError(t, err, "migration 1_create_users has no up script")
+	})
+
+	t.Run("should reject files that aren't migrations", func(t *testing.T) {
+		_, err := loadMigrations(fstest.MapFS{"create_users.sql": {Data: []byte("CREATE TABLE users (id uuid);")}})
+
+		assert.EqualError(t, err, "unexpected migration file create_users.sql")
+	})
+
+	t.Run("should load the embedded migrations", func(t *testing.T) {
+		migrator, err := NewMigrator(nil, logging.Discard())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 11, migrator.Latest())
+		for _, migration := range migrator.migrations {
+			assert.NotEmpty(t, migration.Down, migration.Name)
+		}
+	})
+}
+
+func TestMigratorUp(t *testing.T) {
+	t.Run("should apply pending migrations in order", func(t *testing.T) {
+		migrator, mock := newTestMigrator(t)
+		first, second, third := migrator.migrations[0], migrator.migrations[1], migrator.migrations[2]
+
+		expectLocked(mock, appliedRows(first))
+		for _, migration := range []Migration{second, third} {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(migration.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(insertApplied).WithArgs(migration.Version, migration.Name, migration.Checksum).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+		mock.ExpectExec(unlockQuery).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := migrator.Up(context.Background())
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back a failed migration and stop", func(t *testing.T) {
+		migrator, mock := newTestMigrator(t)
+		first := migrator.migrations[0]
+
+		expectLocked(mock, appliedRows())
+		mock.ExpectQuery(liquibaseCheck).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(first.Up)).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		mock.ExpectExec(unlockQuery).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := migrator.Up(context.Background())
+
+		assert.EqualError(t, err, "migration 1_create_users failed: syntax error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse to run when an applied migration has changed", func(t *testing.T) {
+		migrator, mock := newTestMigrator(t)
+		changed := migrator.migrations[0]
+		changed.Checksum = "0000"
+
+		expectLocked(mock, appliedRows(changed))
+		mock.ExpectExec(unlockQuery).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := migrator.Up(context.Background())
+
+		assert.EqualError(t, err, "migration 1_create_users has changed since it was applied")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse to run against a newer database", func(t *testing.T) {
+		migrator, mock := newTestMigrator(t)
+
+		expectLocked(mock, appliedRows(Migration{Version: 11, Name: "add_cart_year", Checksum: "abcd"}))
+		mock.ExpectExec(unlockQuery).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := migrator.Up(context.Background())
+
+		assert.EqualError(t, err, "database has migration 11_add_cart_year, which this build doesn't know about")
+	})
+
+	t.Run("should adopt changeSets applied by liquibase", func(t *testing.T) {
+		migrator, mock := newTestMigrator(t)
+		first, second, third := migrator.migrations[0], migrator.migrations[1], migrator.migrations[2]
+
+		expectLocked(mock, appliedRows())
+		mock.ExpectQuery(liquibaseCheck).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM databasechangelog`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1").AddRow("2"))
+		for _, migration := range []Migration{first, second} {
+			mock.ExpectExec(insertApplied).WithArgs(migration.Version, migration.Name, migration.Checksum).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(third.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(insertApplied).WithArgs(third.Version, third.Name, third.Checksum).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec(unlockQuery).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := migrator.Up(context.Background())
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMigratorDown(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	first, second := migrator.migrations[0], migrator.migrations[1]
+
+	expectLocked(mock, appliedRows(first, second))
+	for _, migration := range []Migration{second, first} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(migration.Down)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM schema_migrations WHERE version = $1`)).WithArgs(migration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	mock.ExpectExec(unlockQuery).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := migrator.Down(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorVersion(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`)).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	version, err := migrator.Version(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.Equal(t, 10, migrator.Latest())
+}
@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE public.users (
+    id uuid DEFAULT uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_name varchar(255) NOT NULL,
+    email varchar(255) NOT NULL UNIQUE,
+    country varchar(255) NOT NULL,
+    created_at timestamptz DEFAULT NOW() NOT NULL,
+    updated_at timestamptz DEFAULT NOW() NOT NULL
+);
@@ -0,0 +1,11 @@
+CREATE TABLE public.movies_cart (
+    user_id uuid NOT NULL,
+    title varchar(255) NOT NULL UNIQUE,
+    imdb_id varchar(255) NOT NULL UNIQUE,
+    year varchar(255) NOT NULL,
+    genre varchar(255) NOT NULL,
+    actors varchar(255) NOT NULL,
+    type varchar(255) NOT NULL,
+    poster varchar(255) NOT NULL,
+    added_at timestamptz DEFAULT NOW()
+);
@@ -0,0 +1 @@
+ALTER TABLE movies_cart DROP CONSTRAINT fk_movies_cart_user;
@@ -0,0 +1,2 @@
+ALTER TABLE movies_cart
+    ADD CONSTRAINT fk_movies_cart_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
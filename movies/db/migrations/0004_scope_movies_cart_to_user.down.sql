@@ -0,0 +1,5 @@
+ALTER TABLE movies_cart DROP CONSTRAINT uq_movies_cart_user_imdb;
+ALTER TABLE movies_cart DROP CONSTRAINT pk_movies_cart;
+ALTER TABLE movies_cart DROP COLUMN id;
+ALTER TABLE movies_cart ADD CONSTRAINT movies_cart_title_key UNIQUE (title);
+ALTER TABLE movies_cart ADD CONSTRAINT movies_cart_imdb_id_key UNIQUE (imdb_id);
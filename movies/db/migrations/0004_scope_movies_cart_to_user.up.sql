@@ -0,0 +1,6 @@
+-- Scope cart uniqueness to a user and give every cart item its own id
+ALTER TABLE movies_cart ADD COLUMN id uuid DEFAULT uuid_generate_v4() NOT NULL;
+ALTER TABLE movies_cart DROP CONSTRAINT movies_cart_title_key;
+ALTER TABLE movies_cart DROP CONSTRAINT movies_cart_imdb_id_key;
+ALTER TABLE movies_cart ADD CONSTRAINT pk_movies_cart PRIMARY KEY (id);
+ALTER TABLE movies_cart ADD CONSTRAINT uq_movies_cart_user_imdb UNIQUE (user_id, imdb_id);
@@ -0,0 +1 @@
+ALTER TABLE movies_cart DROP COLUMN position;
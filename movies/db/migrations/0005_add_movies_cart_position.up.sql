@@ -0,0 +1,12 @@
+-- Explicit per-user ordering of cart items
+ALTER TABLE movies_cart ADD COLUMN position integer;
+
+UPDATE movies_cart AS mc
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY added_at, id) AS position
+    FROM movies_cart
+) AS ordered
+WHERE mc.id = ordered.id;
+
+ALTER TABLE movies_cart ALTER COLUMN position SET NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE public.movie_provider_cache;
@@ -0,0 +1,9 @@
+-- Cache for movie provider responses
+CREATE TABLE public.movie_provider_cache (
+    cache_key varchar(512) NOT NULL PRIMARY KEY,
+    value bytea NOT NULL,
+    expires_at timestamptz NOT NULL,
+    last_accessed_at timestamptz DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_movie_provider_cache_last_accessed_at ON movie_provider_cache (last_accessed_at);
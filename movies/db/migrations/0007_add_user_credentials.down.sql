@@ -0,0 +1,3 @@
+DROP TABLE public.refresh_tokens;
+
+ALTER TABLE public.users DROP COLUMN password_hash;
@@ -0,0 +1,12 @@
+-- users created before passwords existed have none and can't log in until one is set
+ALTER TABLE public.users ADD COLUMN password_hash varchar(255);
+
+CREATE TABLE public.refresh_tokens (
+    id uuid NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON public.refresh_tokens (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE public.users ADD COLUMN role varchar(16) DEFAULT 'user' NOT NULL;
+
+ALTER TABLE public.users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
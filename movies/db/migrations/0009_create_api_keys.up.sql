@@ -0,0 +1,14 @@
+-- only a hash of each key is kept; the key itself is shown once, when it is created
+CREATE TABLE public.api_keys (
+    id uuid DEFAULT uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+    name varchar(100) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash char(64) NOT NULL UNIQUE,
+    scopes text[] NOT NULL,
+    created_at timestamptz DEFAULT NOW() NOT NULL,
+    last_used_at timestamptz,
+    revoked_at timestamptz
+);
+
+CREATE INDEX idx_api_keys_user_id ON public.api_keys (user_id);
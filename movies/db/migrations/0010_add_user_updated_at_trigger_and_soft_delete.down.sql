@@ -0,0 +1,7 @@
+DROP INDEX public.idx_users_created_at;
+
+ALTER TABLE public.users DROP COLUMN deleted_at;
+
+DROP TRIGGER users_set_updated_at ON public.users;
+
+DROP FUNCTION public.set_updated_at();
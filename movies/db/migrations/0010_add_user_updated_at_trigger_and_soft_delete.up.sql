@@ -0,0 +1,18 @@
+-- keeps updated_at current on every update that changes the row
+CREATE OR REPLACE FUNCTION public.set_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_set_updated_at
+    BEFORE UPDATE ON public.users
+    FOR EACH ROW
+    WHEN (OLD IS DISTINCT FROM NEW)
+    EXECUTE FUNCTION public.set_updated_at();
+
+-- deleted users keep their row, and their email, until they are restored
+ALTER TABLE public.users ADD COLUMN deleted_at timestamptz;
+
+CREATE INDEX idx_users_created_at ON public.users (created_at);
@@ -0,0 +1 @@
+ALTER TABLE movies_cart DROP CONSTRAINT uq_movies_cart_user_position;
@@ -0,0 +1,12 @@
+-- Keep two adds racing for the end of a cart from sharing a position. Carts
+-- that already ended up with duplicates are renumbered in their current order.
+-- Deferrable, so a reorder can swap positions within its transaction.
+UPDATE movies_cart AS mc
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY position, added_at, id) AS position
+    FROM movies_cart
+) AS ordered
+WHERE mc.id = ordered.id AND mc.position <> ordered.position;
+
+ALTER TABLE movies_cart ADD CONSTRAINT uq_movies_cart_user_position UNIQUE (user_id, position) DEFERRABLE INITIALLY IMMEDIATE;
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go-movie-api/configs"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should log json with the request id and secrets redacted", func(t *testing.T) {
+		var out bytes.Buffer
+		logger, err := New(configs.LogConfig{Level: "info", Format: "json"}, &out)
+		assert.NoError(t, err)
+
+		ctx := WithRequestID(context.Background(), "req-1")
+		logger.DebugContext(ctx, "hidden")
+		logger.InfoContext(ctx, "calling provider", "apikey", "secret-key", "title", "Inception")
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+		assert.Equal(t, "calling provider", line["msg"])
+		assert.Equal(t, "req-1", line["request_id"])
+		assert.Equal(t, "REDACTED", line["apikey"])
+		assert.Equal(t, "Inception", line["title"])
+	})
+
+	t.Run("should log text at debug level", func(t *testing.T) {
+		var out bytes.Buffer
+		logger, err := New(configs.LogConfig{Level: "DEBUG", Format: "text"}, &out)
+		assert.NoError(t, err)
+
+		logger.With("component", "cache").Debug("miss")
+
+		assert.Contains(t, out.String(), "level=DEBUG msg=miss component=cache")
+	})
+
+	t.Run("should reject unknown levels and formats", func(t *testing.T) {
+		_, err := New(configs.LogConfig{Level: "loud", Format: "json"}, &bytes.Buffer{})
+		assert.EqualError(t, err, `invalid log level "loud"`)
+
+		_, err = New(configs.LogConfig{Level: "info", Format: "xml"}, &bytes.Buffer{})
+		assert.EqualError(t, err, `invalid log format "xml", expected json or text`)
+	})
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("https://www.omdbapi.com/?apikey=secret&s=Inception")
+	assert.Equal(t, "https://www.omdbapi.com/?apikey=REDACTED&s=Inception", RedactURL(u))
+	assert.Equal(t, "https://www.omdbapi.com/?apikey=secret&s=Inception", u.String())
+
+	u, _ = url.Parse("https://api.themoviedb.org/3/movie/1?api_key=secret")
+	assert.Equal(t, "https://api.themoviedb.org/3/movie/1?api_key=REDACTED", RedactURL(u))
+
+	u, _ = url.Parse("https://example.com/?s=Inception")
+	assert.Equal(t, "https://example.com/?s=Inception", RedactURL(u))
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"go-movie-api/movies/auth"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key of requests made by other services.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves an API key to the principal it acts as.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (auth.Principal, error)
+}
+
+// Authenticate lets a request through only with a valid API key in its
+// X-API-Key header or access token in its Authorization header, and puts
+// the principal on the request context.
+func Authenticate(tokens *auth.TokenManager, keys APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticate(c, tokens, keys) {
+			c.Next()
+		}
+	}
+}
+
+// OptionalAuthenticate is Authenticate for routes that are open to anyone.
+// Requests without credentials go through anonymously, but bad credentials
+// are still rejected.
+func OptionalAuthenticate(tokens *auth.TokenManager, keys APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(APIKeyHeader) == "" && c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		if authenticate(c, tokens, keys) {
+			c.Next()
+		}
+	}
+}
+
+// authenticate sets the principal on the request, or aborts it and reports false.
+func authenticate(c *gin.Context, tokens *auth.TokenManager, keys APIKeyAuthenticator) bool {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		principal, err := keys.AuthenticateAPIKey(c.Request.Context(), key)
+		if err != nil {
+			abortWithError(c, err)
+			return false
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		return true
+	}
+
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		abortWithError(c, auth.ErrAuthenticationRequired)
+		return false
+	}
+
+	claims, err := tokens.ParseAccess(strings.TrimSpace(token))
+	if err != nil {
+		abortWithError(c, err)
+		return false
+	}
+
+	c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), auth.Principal{UserID: claims.Subject, Role: claims.Role}))
+	return true
+}
+
+// RequireRole lets through only principals with one of the given roles. It
+// goes after Authenticate.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			abortWithError(c, auth.ErrAuthenticationRequired)
+			return
+		}
+		if !slices.Contains(roles, principal.Role) {
+			abortWithError(c, auth.ErrForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope stops API keys without the given scope. Anonymous requests
+// and users signed in with a token go through; Authenticate decides whether
+// they may.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if ok && !principal.HasScope(scope) {
+			abortWithError(c, auth.ErrForbidden)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"go-movie-api/configs"
+	"go-movie-api/movies/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubKeys accepts "good-key" as a key of user 456.
+type stubKeys struct{}
+
+func (stubKeys) AuthenticateAPIKey(_ context.Context, key string) (auth.Principal, error) {
+	if key != "good-key" {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+	return auth.Principal{UserID: "456", Role: auth.RoleUser, APIKeyID: "key-1", Scopes: []string{auth.ScopeSearch}}, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokens, err := auth.NewTokenManager(configs.AuthConfig{
+		JwtSecret:              "test-secret-that-is-at-least-32-bytes",
+		AccessTokenTTLSeconds:  60,
+		RefreshTokenTTLSeconds: 3600,
+	})
+	assert.NoError(t, err)
+
+	whoami := func(c *gin.Context) {
+		userId, _ := auth.UserID(c.Request.Context())
+		c.String(http.StatusOK, userId)
+	}
+	router := gin.New()
+	router.GET("/me", Authenticate(tokens, stubKeys{}), whoami)
+	router.GET("/search", OptionalAuthenticate(tokens, stubKeys{}), RequireScope(auth.ScopeSearch), whoami)
+	router.GET("/cart", Authenticate(tokens, stubKeys{}), RequireScope(auth.ScopeCartRead), whoami)
+
+	access, err := tokens.IssueAccess("123", auth.RoleUser)
+	assert.NoError(t, err)
+	refresh, err := tokens.IssueRefresh("123")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		apiKey        string
+		status        int
+		user          string
+	}{
+		{"should let a valid access token through", "/me", "Bearer " + access.Value, "", http.StatusOK, "123"},
+		{"should accept the scheme in any case", "/me", "bearer " + access.Value, "", http.StatusOK, "123"},
+		{"should reject a missing header", "/me", "", "", http.StatusUnauthorized, ""},
+		{"should reject another scheme", "/me", "Basic " + access.Value, "", http.StatusUnauthorized, ""},
+		{"should reject a refresh token", "/me", "Bearer " + refresh.Value, "", http.StatusUnauthorized, ""},
+		{"should reject a malformed token", "/me", "Bearer nonsense", "", http.StatusUnauthorized, ""},
+		{"should let a valid api key through as its owner", "/me", "", "good-key", http.StatusOK, "456"},
+		{"should reject an unknown api key", "/me", "", "bad-key", http.StatusUnauthorized, ""},
+		{"should let anonymous requests through optional authentication", "/search", "", "", http.StatusOK, ""},
+		{"should reject bad credentials even when optional", "/search", "", "bad-key", http.StatusUnauthorized, ""},
+		{"should let an api key with the scope through", "/search", "", "good-key", http.StatusOK, "456"},
+		{"should forbid an api key without the scope", "/cart", "", "good-key", http.StatusForbidden, ""},
+		{"should give users signed in with a token every scope", "/cart", "Bearer " + access.Value, "", http.StatusOK, "123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.status, resp.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.user, resp.Body.String())
+			}
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", resp.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/admin", func(c *gin.Context) {
+		if role := c.GetHeader("X-Test-Role"); role != "" {
+			principal := auth.Principal{UserID: "123", Role: role}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+	}, RequireRole(auth.RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		role   string
+		status int
+	}{
+		{"should let an admin through", auth.RoleAdmin, http.StatusNoContent},
+		{"should forbid a user", auth.RoleUser, http.StatusForbidden},
+		{"should reject an anonymous request", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.Header.Set("X-Test-Role", tt.role)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.status, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/client"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ErrRouteNotFound is the error of requests that match no route.
+var ErrRouteNotFound = apperror.NotFound("route_not_found", "no such route")
+
+// Errors answers requests whose handler failed with c.Error with problem
+// details for the last error. It goes after AccessLog and Metrics, so they
+// see the status it writes.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// NoRoute answers requests that match no route with problem details.
+func NoRoute(c *gin.Context) {
+	abortWithError(c, ErrRouteNotFound)
+}
+
+// abortWithError stops the request with problem details for err.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	writeProblem(c, err)
+	c.Abort()
+}
+
+func writeProblem(c *gin.Context, err error) {
+	appErr := apperror.From(err)
+	status := problemStatus(appErr.Kind)
+
+	var upstreamErr *client.UpstreamError
+	if errors.As(err, &upstreamErr) && upstreamErr.RetryAfter > 0 {
+		c.Header("Retry-After", ceilSeconds(upstreamErr.RetryAfter))
+	}
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", "Bearer")
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, model.Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    appErr.Detail,
+		Instance:  c.Request.URL.Path,
+		Code:      appErr.Code,
+		RequestID: logging.RequestID(c.Request.Context()),
+		Errors:    appErr.Fields,
+	})
+}
+
+func problemStatus(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindValidation:
+		return http.StatusBadRequest
+	case apperror.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindRateLimited:
+		return http.StatusTooManyRequests
+	case apperror.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case apperror.KindBadGateway:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
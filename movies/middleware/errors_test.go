@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/client"
+	"go-movie-api/movies/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		detail     string
+		retryAfter string
+	}{
+		{"should answer not found errors with 404", apperror.NotFound("user_not_found", "user not found"), http.StatusNotFound, "user_not_found", "user not found", ""},
+		{"should answer conflicts with 409", apperror.Conflict("movie_already_in_cart", "movie already added to the cart"), http.StatusConflict, "movie_already_in_cart", "movie already added to the cart", ""},
+		{"should answer validation errors with 400", apperror.Validation("invalid_request", "title is required"), http.StatusBadRequest, "invalid_request", "title is required", ""},
+		{"should find typed errors that are wrapped", fmt.Errorf("adding to cart: %w", apperror.Conflict("movie_already_in_cart", "movie already added to the cart")), http.StatusConflict, "movie_already_in_cart", "movie already added to the cart", ""},
+		{"should answer upstream errors by their kind", &client.UpstreamError{Kind: client.ErrUpstreamUnavailable, StatusCode: 503, RetryAfter: 1500 * time.Millisecond}, http.StatusServiceUnavailable, "provider_unavailable", "movie provider is unavailable", "2"},
+		{"should hide the message of untyped errors", errors.New(`pq: relation "users" does not exist`), http.StatusInternalServerError, apperror.InternalCode, "something went wrong, please try again later", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Errors())
+			router.GET("/fail", func(c *gin.Context) {
+				_ = c.Error(tt.err)
+			})
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+			assert.Equal(t, tt.status, resp.Code)
+			assert.Equal(t, ProblemContentType, resp.Header().Get("Content-Type"))
+			assert.Equal(t, tt.retryAfter, resp.Header().Get("Retry-After"))
+
+			var problem model.Problem
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+			assert.Equal(t, model.Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(tt.status),
+				Status:   tt.status,
+				Detail:   tt.detail,
+				Instance: "/fail",
+				Code:     tt.code,
+			}, problem)
+		})
+	}
+
+	t.Run("should leave responses the handler already wrote alone", func(t *testing.T) {
+		router := gin.New()
+		router.Use(Errors())
+		router.GET("/partial", func(c *gin.Context) {
+			c.String(http.StatusAccepted, "done")
+			_ = c.Error(errors.New("cleanup failed"))
+		})
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/partial", nil))
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, "done", resp.Body.String())
+	})
+
+	t.Run("should answer unknown routes with problem details", func(t *testing.T) {
+		router := gin.New()
+		router.Use(RequestID(), Errors())
+		router.NoRoute(NoRoute)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		var problem model.Problem
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+		assert.Equal(t, "route_not_found", problem.Code)
+		assert.Equal(t, resp.Header().Get("X-Request-ID"), problem.RequestID)
+	})
+}
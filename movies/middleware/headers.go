@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl lets the client keep successful responses for maxAge. They
+// are private, as they carry the client's own RateLimit headers, which a
+// shared cache would replay to everyone. Problem details are never cached,
+// the Errors middleware marks them no-store.
+func CacheControl(maxAge time.Duration) gin.HandlerFunc {
+	value := "private, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
+}
+
+// Deprecated marks the routes it is on as deprecated since the given time,
+// with the Deprecation header of RFC 9745, and points to the route replacing
+// them when successor is set.
+func Deprecated(since time.Time, successor string) gin.HandlerFunc {
+	return DeprecatedPerRequest(since, func(*gin.Context) string { return successor })
+}
+
+// DeprecatedPerRequest is Deprecated for routes whose successor depends on
+// the request, such as on its path parameters or its user.
+func DeprecatedPerRequest(since time.Time, successor func(c *gin.Context) string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if successor := successor(c); successor != "" {
+			c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
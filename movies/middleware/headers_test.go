@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Errors(), CacheControl(10*time.Minute))
+	router.GET("/movies", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			_ = c.Error(errors.New("provider down"))
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/movies", nil))
+	assert.Equal(t, "private, max-age=600", resp.Header().Get("Cache-Control"))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/movies?fail=1", nil))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+}
+
+func TestDeprecated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	since := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	router := gin.New()
+	router.POST("/movies/search", Deprecated(since, "/api/v1/movies"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/movies/cart/list", Deprecated(since, ""), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/users/:userId/cart/list", DeprecatedPerRequest(since, func(c *gin.Context) string {
+		return "/api/v1/users/" + c.Param("userId") + "/cart"
+	}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/movies/search", nil))
+	assert.Equal(t, "@1792281600", resp.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/movies>; rel="successor-version"`, resp.Header().Get("Link"))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/movies/cart/list", nil))
+	assert.Equal(t, "@1792281600", resp.Header().Get("Deprecation"))
+	assert.Empty(t, resp.Header().Get("Link"))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/users/7/cart/list", nil))
+	assert.Equal(t, "@1792281600", resp.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/users/7/cart>; rel="successor-version"`, resp.Header().Get("Link"))
+}
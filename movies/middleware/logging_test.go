@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-movie-api/configs"
+	"go-movie-api/movies/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	logger, err := logging.New(configs.LogConfig{Level: "info", Format: "json"}, &out)
+	assert.NoError(t, err)
+
+	var seen string
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(logger))
+	router.GET("/movies", func(ctx *gin.Context) {
+		seen = logging.RequestID(ctx.Request.Context())
+		ctx.Status(http.StatusNoContent)
+	})
+
+	serve := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+		if header != "" {
+			req.Header.Set(logging.RequestIDHeader, header)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("should keep the id sent by the client", func(t *testing.T) {
+		out.Reset()
+		resp := serve("abc-123")
+
+		assert.Equal(t, "abc-123", resp.Header().Get(logging.RequestIDHeader))
+		assert.Equal(t, "abc-123", seen)
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+		assert.Equal(t, "abc-123", line["request_id"])
+		assert.Equal(t, "/movies", line["route"])
+		assert.Equal(t, float64(http.StatusNoContent), line["status"])
+	})
+
+	t.Run("should generate an id when there is none or it is unusable", func(t *testing.T) {
+		for _, header := range []string{"", "not a valid id\n"} {
+			resp := serve(header)
+
+			id := resp.Header().Get(logging.RequestIDHeader)
+			assert.Len(t, id, 32)
+			assert.Equal(t, id, seen)
+		}
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	logger, err := logging.New(configs.LogConfig{Level: "info", Format: "json"}, &out)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Recovery(logger))
+	router.GET("/boom", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, out.String(), `"panic":"boom"`)
+}
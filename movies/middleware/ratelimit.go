@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"go-movie-api/configs"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/auth"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrTooManyRequests is the error of requests over their client's limit.
+var ErrTooManyRequests = apperror.RateLimited("too_many_requests", "too many requests, slow down")
+
+// RateLimiter keeps a token bucket per client: Burst requests at once,
+// refilled at the rule's rate. Buckets that have filled up again are
+// dropped, so idle clients cost nothing.
+type RateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimit is the outcome of one request against a bucket.
+type rateLimit struct {
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration // until the next token, when not allowed
+	reset      time.Duration // until the bucket is full again
+}
+
+// NewRateLimiter returns nil for a rule without a rate; RateLimit lets
+// everything through a nil limiter.
+func NewRateLimiter(rule configs.RateLimitRule) *RateLimiter {
+	if rule.RequestsPerMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		rate:    float64(rule.RequestsPerMinute) / 60,
+		burst:   float64(max(rule.Burst, 1)),
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (rl *RateLimiter) take(key string) rateLimit {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rl.burst, updated: now}
+		rl.buckets[key] = b
+	}
+	b.tokens = min(rl.burst, b.tokens+now.Sub(b.updated).Seconds()*rl.rate)
+	b.updated = now
+
+	result := rateLimit{allowed: b.tokens >= 1}
+	if result.allowed {
+		b.tokens--
+	} else {
+		result.retryAfter = rl.secondsFor(1 - b.tokens)
+	}
+	result.remaining = int(b.tokens)
+	result.reset = rl.secondsFor(rl.burst - b.tokens)
+	return result
+}
+
+// sweep drops the buckets that are full again, at most once per refill period.
+func (rl *RateLimiter) sweep(now time.Time) {
+	fill := rl.secondsFor(rl.burst)
+	if now.Sub(rl.lastSweep) < fill {
+		return
+	}
+	rl.lastSweep = now
+	for key, b := range rl.buckets {
+		if now.Sub(b.updated) >= fill {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func (rl *RateLimiter) secondsFor(tokens float64) time.Duration {
+	return time.Duration(tokens / rl.rate * float64(time.Second))
+}
+
+// RateLimit limits each client of the routes it is on: API keys, users and,
+// for anonymous requests, IP addresses each get their own bucket. It goes
+// after Authenticate, and answers with the RateLimit-* headers of the IETF
+// draft on every request and 429 with Retry-After once the bucket is empty.
+func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		result := limiter.take(clientKey(c))
+		c.Header("RateLimit-Limit", strconv.Itoa(int(limiter.burst)))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.reset))
+		c.Header("RateLimit-Policy", strconv.Itoa(int(limiter.burst))+";w="+ceilSeconds(limiter.secondsFor(limiter.burst)))
+
+		if !result.allowed {
+			c.Header("Retry-After", ceilSeconds(result.retryAfter))
+			abortWithError(c, ErrTooManyRequests)
+			return
+		}
+		c.Next()
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	principal, ok := auth.FromContext(c.Request.Context())
+	switch {
+	case ok && principal.APIKeyID != "":
+		return "key:" + principal.APIKeyID
+	case ok:
+		return "user:" + principal.UserID
+	default:
+		return "ip:" + c.ClientIP()
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
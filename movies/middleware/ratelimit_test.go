@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"go-movie-api/configs"
+	"go-movie-api/movies/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitedRouter(limiter *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/search", func(c *gin.Context) {
+		if userId := c.GetHeader("X-Test-User"); userId != "" {
+			principal := auth.Principal{UserID: userId, Role: auth.RoleUser}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+	}, RateLimit(limiter), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func search(router *gin.Engine, userId string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	if userId != "" {
+		req.Header.Set("X-Test-User", userId)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("should allow a burst and then answer 429 until a token is back", func(t *testing.T) {
+		clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		limiter := NewRateLimiter(configs.RateLimitRule{RequestsPerMinute: 60, Burst: 2})
+		limiter.now = func() time.Time { return clock }
+		router := newRateLimitedRouter(limiter)
+
+		first := search(router, "")
+		assert.Equal(t, http.StatusNoContent, first.Code)
+		assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", first.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=2", first.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, http.StatusNoContent, search(router, "").Code)
+
+		limited := search(router, "")
+		assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+		assert.Equal(t, "0", limited.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", limited.Header().Get("Retry-After"))
+		assert.Equal(t, ProblemContentType, limited.Header().Get("Content-Type"))
+		assert.Contains(t, limited.Body.String(), `"code":"too_many_requests"`)
+
+		clock = clock.Add(time.Second)
+		assert.Equal(t, http.StatusNoContent, search(router, "").Code)
+	})
+
+	t.Run("should give every user a bucket of their own", func(t *testing.T) {
+		router := newRateLimitedRouter(NewRateLimiter(configs.RateLimitRule{RequestsPerMinute: 1, Burst: 1}))
+
+		assert.Equal(t, http.StatusNoContent, search(router, "123").Code)
+		assert.Equal(t, http.StatusTooManyRequests, search(router, "123").Code)
+		assert.Equal(t, http.StatusNoContent, search(router, "456").Code)
+		assert.Equal(t, http.StatusNoContent, search(router, "").Code)
+		assert.Equal(t, http.StatusTooManyRequests, search(router, "").Code)
+	})
+
+	t.Run("should let everything through without a rate", func(t *testing.T) {
+		router := newRateLimitedRouter(NewRateLimiter(configs.RateLimitRule{}))
+
+		for i := 0; i < 5; i++ {
+			resp := search(router, "")
+			assert.Equal(t, http.StatusNoContent, resp.Code)
+			assert.Empty(t, resp.Header().Get("RateLimit-Limit"))
+		}
+	})
+}
@@ -1,19 +1,19 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: movies/client/client.go
+// Source: movies/client/movie_client.go
 //
 // Generated by this command:
 //
-//	mockgen -source=movies/client/client.go -destination=movies/mock/client_mock.go -package=mock
+//	mockgen -source=movies/client/movie_client.go -destination=movies/mock/client_mock.go -package=mock
 //
 
 // Package mock is a generated GoMock package.
 package mock
 
 import (
+	context "context"
 	model "go-movie-api/movies/model"
 	reflect "reflect"
 
-	gin "github.com/gin-gonic/gin"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,7 +42,7 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 }
 
 // GetMovieDetails mocks base method.
-func (m *MockClient) GetMovieDetails(ctx *gin.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
+func (m *MockClient) GetMovieDetails(ctx context.Context, request model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetMovieDetails", ctx, request)
 	ret0, _ := ret[0].(model.GetMovieDetailsResponse)
@@ -57,7 +57,7 @@ func (mr *MockClientMockRecorder) GetMovieDetails(ctx, request any) *gomock.Call
 }
 
 // GetMovieDetailsById mocks base method.
-func (m *MockClient) GetMovieDetailsById(ctx *gin.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
+func (m *MockClient) GetMovieDetailsById(ctx context.Context, request model.AddMovieToCartRequest) (model.GetMovieDetailsResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetMovieDetailsById", ctx, request)
 	ret0, _ := ret[0].(model.GetMovieDetailsResponse)
@@ -72,7 +72,7 @@ func (mr *MockClientMockRecorder) GetMovieDetailsById(ctx, request any) *gomock.
 }
 
 // SearchMovies mocks base method.
-func (m *MockClient) SearchMovies(ctx *gin.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
+func (m *MockClient) SearchMovies(ctx context.Context, request model.SearchMovieRequest) (model.SearchMovieResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SearchMovies", ctx, request)
 	ret0, _ := ret[0].(model.SearchMovieResponse)
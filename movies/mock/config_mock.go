@@ -10,6 +10,7 @@
 package mock
 
 import (
+	configs "go-movie-api/configs"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -53,6 +54,90 @@ func (mr *MockConfigMockRecorder) GetApiKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKey", reflect.TypeOf((*MockConfig)(nil).GetApiKey))
 }
 
+// GetAuthConfig mocks base method.
+func (m *MockConfig) GetAuthConfig() configs.AuthConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthConfig")
+	ret0, _ := ret[0].(configs.AuthConfig)
+	return ret0
+}
+
+// GetAuthConfig indicates an expected call of GetAuthConfig.
+func (mr *MockConfigMockRecorder) GetAuthConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthConfig", reflect.TypeOf((*MockConfig)(nil).GetAuthConfig))
+}
+
+// GetBreakerConfig mocks base method.
+func (m *MockConfig) GetBreakerConfig() configs.BreakerConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBreakerConfig")
+	ret0, _ := ret[0].(configs.BreakerConfig)
+	return ret0
+}
+
+// GetBreakerConfig indicates an expected call of GetBreakerConfig.
+func (mr *MockConfigMockRecorder) GetBreakerConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBreakerConfig", reflect.TypeOf((*MockConfig)(nil).GetBreakerConfig))
+}
+
+// GetCacheConfig mocks base method.
+func (m *MockConfig) GetCacheConfig() configs.CacheConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCacheConfig")
+	ret0, _ := ret[0].(configs.CacheConfig)
+	return ret0
+}
+
+// GetCacheConfig indicates an expected call of GetCacheConfig.
+func (mr *MockConfigMockRecorder) GetCacheConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCacheConfig", reflect.TypeOf((*MockConfig)(nil).GetCacheConfig))
+}
+
+// GetDatabaseConfig mocks base method.
+func (m *MockConfig) GetDatabaseConfig() configs.DatabaseConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDatabaseConfig")
+	ret0, _ := ret[0].(configs.DatabaseConfig)
+	return ret0
+}
+
+// GetDatabaseConfig indicates an expected call of GetDatabaseConfig.
+func (mr *MockConfigMockRecorder) GetDatabaseConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDatabaseConfig", reflect.TypeOf((*MockConfig)(nil).GetDatabaseConfig))
+}
+
+// GetHttpClientConfig mocks base method.
+func (m *MockConfig) GetHttpClientConfig() configs.HttpClientConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHttpClientConfig")
+	ret0, _ := ret[0].(configs.HttpClientConfig)
+	return ret0
+}
+
+// GetHttpClientConfig indicates an expected call of GetHttpClientConfig.
+func (mr *MockConfigMockRecorder) GetHttpClientConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHttpClientConfig", reflect.TypeOf((*MockConfig)(nil).GetHttpClientConfig))
+}
+
+// GetLogConfig mocks base method.
+func (m *MockConfig) GetLogConfig() configs.LogConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogConfig")
+	ret0, _ := ret[0].(configs.LogConfig)
+	return ret0
+}
+
+// GetLogConfig indicates an expected call of GetLogConfig.
+func (mr *MockConfigMockRecorder) GetLogConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogConfig", reflect.TypeOf((*MockConfig)(nil).GetLogConfig))
+}
+
 // GetPort mocks base method.
 func (m *MockConfig) GetPort() string {
 	m.ctrl.T.Helper()
@@ -67,6 +152,62 @@ func (mr *MockConfigMockRecorder) GetPort() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPort", reflect.TypeOf((*MockConfig)(nil).GetPort))
 }
 
+// GetProvidersConfig mocks base method.
+func (m *MockConfig) GetProvidersConfig() configs.ProvidersConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvidersConfig")
+	ret0, _ := ret[0].(configs.ProvidersConfig)
+	return ret0
+}
+
+// GetProvidersConfig indicates an expected call of GetProvidersConfig.
+func (mr *MockConfigMockRecorder) GetProvidersConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvidersConfig", reflect.TypeOf((*MockConfig)(nil).GetProvidersConfig))
+}
+
+// GetRateLimitConfig mocks base method.
+func (m *MockConfig) GetRateLimitConfig() configs.RateLimitConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRateLimitConfig")
+	ret0, _ := ret[0].(configs.RateLimitConfig)
+	return ret0
+}
+
+// GetRateLimitConfig indicates an expected call of GetRateLimitConfig.
+func (mr *MockConfigMockRecorder) GetRateLimitConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRateLimitConfig", reflect.TypeOf((*MockConfig)(nil).GetRateLimitConfig))
+}
+
+// GetServerConfig mocks base method.
+func (m *MockConfig) GetServerConfig() configs.ServerConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerConfig")
+	ret0, _ := ret[0].(configs.ServerConfig)
+	return ret0
+}
+
+// GetServerConfig indicates an expected call of GetServerConfig.
+func (mr *MockConfigMockRecorder) GetServerConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerConfig", reflect.TypeOf((*MockConfig)(nil).GetServerConfig))
+}
+
+// GetTracingConfig mocks base method.
+func (m *MockConfig) GetTracingConfig() configs.TracingConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTracingConfig")
+	ret0, _ := ret[0].(configs.TracingConfig)
+	return ret0
+}
+
+// GetTracingConfig indicates an expected call of GetTracingConfig.
+func (mr *MockConfigMockRecorder) GetTracingConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTracingConfig", reflect.TypeOf((*MockConfig)(nil).GetTracingConfig))
+}
+
 // SearchMoviesUrl mocks base method.
 func (m *MockConfig) SearchMoviesUrl() string {
 	m.ctrl.T.Helper()
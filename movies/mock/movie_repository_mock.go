@@ -10,6 +10,7 @@
 package mock
 
 import (
+	context "context"
 	model "go-movie-api/movies/model"
 	reflect "reflect"
 
@@ -41,30 +42,87 @@ func (m *MockMovieRespository) EXPECT() *MockMovieRespositoryMockRecorder {
 }
 
 // AddToMovieCart mocks base method.
-func (m *MockMovieRespository) AddToMovieCart(movie model.GetMovieDetailsResponse, userId string) error {
+func (m *MockMovieRespository) AddToMovieCart(ctx context.Context, movie model.GetMovieDetailsResponse, userId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddToMovieCart", movie, userId)
+	ret := m.ctrl.Call(m, "AddToMovieCart", ctx, movie, userId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddToMovieCart indicates an expected call of AddToMovieCart.
-func (mr *MockMovieRespositoryMockRecorder) AddToMovieCart(movie, userId any) *gomock.Call {
+func (mr *MockMovieRespositoryMockRecorder) AddToMovieCart(ctx, movie, userId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToMovieCart", reflect.TypeOf((*MockMovieRespository)(nil).AddToMovieCart), movie, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToMovieCart", reflect.TypeOf((*MockMovieRespository)(nil).AddToMovieCart), ctx, movie, userId)
+}
+
+// ClearMovieCart mocks base method.
+func (m *MockMovieRespository) ClearMovieCart(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearMovieCart", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearMovieCart indicates an expected call of ClearMovieCart.
+func (mr *MockMovieRespositoryMockRecorder) ClearMovieCart(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearMovieCart", reflect.TypeOf((*MockMovieRespository)(nil).ClearMovieCart), ctx, userId)
 }
 
 // GetMoviesInCart mocks base method.
-func (m *MockMovieRespository) GetMoviesInCart(userId string) ([]model.MovieDetailsInCart, error) {
+func (m *MockMovieRespository) GetMoviesInCart(ctx context.Context, userId string) ([]model.MovieDetailsInCart, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMoviesInCart", userId)
+	ret := m.ctrl.Call(m, "GetMoviesInCart", ctx, userId)
 	ret0, _ := ret[0].([]model.MovieDetailsInCart)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMoviesInCart indicates an expected call of GetMoviesInCart.
-func (mr *MockMovieRespositoryMockRecorder) GetMoviesInCart(userId any) *gomock.Call {
+func (mr *MockMovieRespositoryMockRecorder) GetMoviesInCart(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesInCart", reflect.TypeOf((*MockMovieRespository)(nil).GetMoviesInCart), ctx, userId)
+}
+
+// IsMovieInCart mocks base method.
+func (m *MockMovieRespository) IsMovieInCart(ctx context.Context, userId, imdbId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMovieInCart", ctx, userId, imdbId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMovieInCart indicates an expected call of IsMovieInCart.
+func (mr *MockMovieRespositoryMockRecorder) IsMovieInCart(ctx, userId, imdbId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMovieInCart", reflect.TypeOf((*MockMovieRespository)(nil).IsMovieInCart), ctx, userId, imdbId)
+}
+
+// RemoveFromMovieCart mocks base method.
+func (m *MockMovieRespository) RemoveFromMovieCart(ctx context.Context, userId, imdbId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromMovieCart", ctx, userId, imdbId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFromMovieCart indicates an expected call of RemoveFromMovieCart.
+func (mr *MockMovieRespositoryMockRecorder) RemoveFromMovieCart(ctx, userId, imdbId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromMovieCart", reflect.TypeOf((*MockMovieRespository)(nil).RemoveFromMovieCart), ctx, userId, imdbId)
+}
+
+// ReorderMovieCart mocks base method.
+func (m *MockMovieRespository) ReorderMovieCart(ctx context.Context, userId string, imdbIds []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderMovieCart", ctx, userId, imdbIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderMovieCart indicates an expected call of ReorderMovieCart.
+func (mr *MockMovieRespositoryMockRecorder) ReorderMovieCart(ctx, userId, imdbIds any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesInCart", reflect.TypeOf((*MockMovieRespository)(nil).GetMoviesInCart), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderMovieCart", reflect.TypeOf((*MockMovieRespository)(nil).ReorderMovieCart), ctx, userId, imdbIds)
 }
@@ -10,10 +10,10 @@
 package mock
 
 import (
+	context "context"
 	model "go-movie-api/movies/model"
 	reflect "reflect"
 
-	gin "github.com/gin-gonic/gin"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,7 +42,7 @@ func (m *MockMovieService) EXPECT() *MockMovieServiceMockRecorder {
 }
 
 // AddMovieToCart mocks base method.
-func (m *MockMovieService) AddMovieToCart(ctx *gin.Context, req model.AddMovieToCartRequest) error {
+func (m *MockMovieService) AddMovieToCart(ctx context.Context, req model.AddMovieToCartRequest) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddMovieToCart", ctx, req)
 	ret0, _ := ret[0].(error)
@@ -55,8 +55,22 @@ func (mr *MockMovieServiceMockRecorder) AddMovieToCart(ctx, req any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMovieToCart", reflect.TypeOf((*MockMovieService)(nil).AddMovieToCart), ctx, req)
 }
 
+// ClearMovieCart mocks base method.
+func (m *MockMovieService) ClearMovieCart(ctx context.Context, req model.ClearMovieCartRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearMovieCart", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearMovieCart indicates an expected call of ClearMovieCart.
+func (mr *MockMovieServiceMockRecorder) ClearMovieCart(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearMovieCart", reflect.TypeOf((*MockMovieService)(nil).ClearMovieCart), ctx, req)
+}
+
 // GetMovieDetails mocks base method.
-func (m *MockMovieService) GetMovieDetails(ctx *gin.Context, req model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
+func (m *MockMovieService) GetMovieDetails(ctx context.Context, req model.GetMovieDetailsRequest) (model.GetMovieDetailsResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetMovieDetails", ctx, req)
 	ret0, _ := ret[0].(model.GetMovieDetailsResponse)
@@ -71,7 +85,7 @@ func (mr *MockMovieServiceMockRecorder) GetMovieDetails(ctx, req any) *gomock.Ca
 }
 
 // GetMoviesInCart mocks base method.
-func (m *MockMovieService) GetMoviesInCart(ctx *gin.Context, req model.GetMoviesInCartReq) ([]model.MovieDetailsInCart, error) {
+func (m *MockMovieService) GetMoviesInCart(ctx context.Context, req model.GetMoviesInCartReq) ([]model.MovieDetailsInCart, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetMoviesInCart", ctx, req)
 	ret0, _ := ret[0].([]model.MovieDetailsInCart)
@@ -85,8 +99,36 @@ func (mr *MockMovieServiceMockRecorder) GetMoviesInCart(ctx, req any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMoviesInCart", reflect.TypeOf((*MockMovieService)(nil).GetMoviesInCart), ctx, req)
 }
 
+// RemoveMovieFromCart mocks base method.
+func (m *MockMovieService) RemoveMovieFromCart(ctx context.Context, req model.RemoveMovieFromCartRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMovieFromCart", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMovieFromCart indicates an expected call of RemoveMovieFromCart.
+func (mr *MockMovieServiceMockRecorder) RemoveMovieFromCart(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMovieFromCart", reflect.TypeOf((*MockMovieService)(nil).RemoveMovieFromCart), ctx, req)
+}
+
+// ReorderMovieCart mocks base method.
+func (m *MockMovieService) ReorderMovieCart(ctx context.Context, req model.ReorderMovieCartRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderMovieCart", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderMovieCart indicates an expected call of ReorderMovieCart.
+func (mr *MockMovieServiceMockRecorder) ReorderMovieCart(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderMovieCart", reflect.TypeOf((*MockMovieService)(nil).ReorderMovieCart), ctx, req)
+}
+
 // SearchMovies mocks base method.
-func (m *MockMovieService) SearchMovies(ctx *gin.Context, req model.SearchMovieRequest) ([]model.Movie, error) {
+func (m *MockMovieService) SearchMovies(ctx context.Context, req model.SearchMovieRequest) ([]model.Movie, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SearchMovies", ctx, req)
 	ret0, _ := ret[0].([]model.Movie)
@@ -0,0 +1,205 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: movies/repository/user_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=movies/repository/user_repository.go -destination=movies/mock/user_repository_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	model "go-movie-api/movies/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserRespository is a mock of UserRespository interface.
+type MockUserRespository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRespositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserRespositoryMockRecorder is the mock recorder for MockUserRespository.
+type MockUserRespositoryMockRecorder struct {
+	mock *MockUserRespository
+}
+
+// NewMockUserRespository creates a new mock instance.
+func NewMockUserRespository(ctrl *gomock.Controller) *MockUserRespository {
+	mock := &MockUserRespository{ctrl: ctrl}
+	mock.recorder = &MockUserRespositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRespository) EXPECT() *MockUserRespositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockUserRespository) CreateUser(ctx context.Context, user model.CreateUserRequest, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserRespositoryMockRecorder) CreateUser(ctx, user, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRespository)(nil).CreateUser), ctx, user, passwordHash)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUserRespository) DeleteUser(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRespositoryMockRecorder) DeleteUser(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRespository)(nil).DeleteUser), ctx, userId)
+}
+
+// GetUser mocks base method.
+func (m *MockUserRespository) GetUser(ctx context.Context, userId string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, userId)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockUserRespositoryMockRecorder) GetUser(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRespository)(nil).GetUser), ctx, userId)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockUserRespository) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockUserRespositoryMockRecorder) GetUserByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUserRespository)(nil).GetUserByEmail), ctx, email)
+}
+
+// GetUserCredentials mocks base method.
+func (m *MockUserRespository) GetUserCredentials(ctx context.Context, email string) (model.UserCredentials, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCredentials", ctx, email)
+	ret0, _ := ret[0].(model.UserCredentials)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCredentials indicates an expected call of GetUserCredentials.
+func (mr *MockUserRespositoryMockRecorder) GetUserCredentials(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCredentials", reflect.TypeOf((*MockUserRespository)(nil).GetUserCredentials), ctx, email)
+}
+
+// GetUserRole mocks base method.
+func (m *MockUserRespository) GetUserRole(ctx context.Context, userId string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRole", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRole indicates an expected call of GetUserRole.
+func (mr *MockUserRespositoryMockRecorder) GetUserRole(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockUserRespository)(nil).GetUserRole), ctx, userId)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserRespository) ListUsers(ctx context.Context, query model.ListUsersQuery) ([]model.User, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, query)
+	ret0, _ := ret[0].([]model.User)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserRespositoryMockRecorder) ListUsers(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserRespository)(nil).ListUsers), ctx, query)
+}
+
+// PromoteFirstAdmin mocks base method.
+func (m *MockUserRespository) PromoteFirstAdmin(ctx context.Context, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteFirstAdmin", ctx, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoteFirstAdmin indicates an expected call of PromoteFirstAdmin.
+func (mr *MockUserRespositoryMockRecorder) PromoteFirstAdmin(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteFirstAdmin", reflect.TypeOf((*MockUserRespository)(nil).PromoteFirstAdmin), ctx, email)
+}
+
+// RestoreUser mocks base method.
+func (m *MockUserRespository) RestoreUser(ctx context.Context, userId string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, userId)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockUserRespositoryMockRecorder) RestoreUser(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockUserRespository)(nil).RestoreUser), ctx, userId)
+}
+
+// SetUserPassword mocks base method.
+func (m *MockUserRespository) SetUserPassword(ctx context.Context, userId, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserPassword", ctx, userId, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserPassword indicates an expected call of SetUserPassword.
+func (mr *MockUserRespositoryMockRecorder) SetUserPassword(ctx, userId, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserPassword", reflect.TypeOf((*MockUserRespository)(nil).SetUserPassword), ctx, userId, passwordHash)
+}
+
+// UpdateUser mocks base method.
+func (m *MockUserRespository) UpdateUser(ctx context.Context, userId string, update model.UpdateUserRequest) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, userId, update)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserRespositoryMockRecorder) UpdateUser(ctx, userId, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserRespository)(nil).UpdateUser), ctx, userId, update)
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// ListAPIKeysQuery is the query string of GET /admin/api-keys.
+type ListAPIKeysQuery struct {
+	UserID string `form:"userId" binding:"omitempty,uuid"`
+}
+
+type CreateAPIKeyRequest struct {
+	UserID string   `json:"userId" binding:"required,uuid"`
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=movies:search cart:read cart:write user:read user:write"`
+}
+
+// APIKey describes a key without the key itself. Prefix is the start of the
+// key, for telling keys apart.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreateAPIKeyResponse is the only time the key is shown.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type RevokeAPIKeyResponse struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,30 @@
+package model
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// TokenResponse is a new session. ExpiresIn is the access token's lifetime in seconds.
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+type LogoutResponse struct {
+	Status string `json:"status"`
+}
+
+// UserCredentials is what a login is checked against. PasswordHash is empty
+// for users who have no password yet.
+type UserCredentials struct {
+	UserId       string `db:"id"`
+	PasswordHash string `db:"password_hash"`
+	Role         string `db:"role"`
+}
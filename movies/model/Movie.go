@@ -9,12 +9,14 @@ type Movie struct {
 	Poster string
 }
 
+// SearchMovieRequest searches by text. Year is a year or a range of years,
+// like 2010-2015.
 type SearchMovieRequest struct {
 	Title       string `json:"title,omitempty"`
-	Type        string `json:"type,omitempty"`
-	Year        string `json:"year,omitempty"`
-	SearchQuery string `json:"searchText" binding:"required"`
-	Page        string `json:"page,omitempty"`
+	Type        string `json:"type,omitempty" binding:"omitempty,movietype"`
+	Year        string `json:"year,omitempty" binding:"omitempty,yearrange"`
+	SearchQuery string `json:"searchText" binding:"required,searchtext"`
+	Page        string `json:"page,omitempty" binding:"omitempty,number"`
 }
 
 type SearchMovieResponse struct {
@@ -55,16 +57,31 @@ type GetMovieDetailsResponse struct {
 	Error      string   `json:"Error"`
 }
 
+// SearchMoviesQuery is the query string of GET /api/v1/movies.
+type SearchMoviesQuery struct {
+	Query string `form:"q" binding:"required,searchtext"`
+	Title string `form:"title"`
+	Type  string `form:"type" binding:"omitempty,movietype"`
+	Year  string `form:"year" binding:"omitempty,yearrange"`
+	Page  string `form:"page" binding:"omitempty,number"`
+}
+
+// MovieURI is the movie in the path of the /api/v1 routes.
+type MovieURI struct {
+	ImdbID string `uri:"imdbId" binding:"required,movieid"`
+}
+
 type GetMovieDetailsRequest struct {
-	Title   string `json:"title,omitempty"`
-	MovieID string `json:"movieId,omitempty"`
-	Type    string `json:"type,omitempty"`
-	Year    string `json:"year,omitempty"`
+	Title   string `json:"title,omitempty" binding:"required_without=MovieID"`
+	MovieID string `json:"movieId,omitempty" binding:"required_without=Title,omitempty,movieid"`
+	Type    string `json:"type,omitempty" binding:"omitempty,movietype"`
+	Year    string `json:"year,omitempty" binding:"omitempty,year"`
 }
 
+// UserID on the cart requests is the authenticated user, never taken from the body.
 type AddMovieToCartRequest struct {
-	MovieID string `json:"movieId" binding:"required"`
-	UserID  string `json:"userId" binding:"required"`
+	MovieID string `json:"movieId" binding:"required,movieid"`
+	UserID  string `json:"-"`
 }
 
 type AddMovieToCartResponse struct {
@@ -72,15 +89,35 @@ type AddMovieToCartResponse struct {
 }
 
 type MovieDetailsInCart struct {
-	Title  string
-	Year   string
-	ImdbID string
-	Actors string
-	Type   string
-	Poster string
-	Genre  string
+	ID       string
+	Title    string
+	Year     string
+	ImdbID   string
+	Actors   string
+	Type     string
+	Poster   string
+	Genre    string
+	Position int
 }
 
 type GetMoviesInCartReq struct {
-	UserID string `json:"userId"`
+	UserID string `json:"-"`
+}
+
+type RemoveMovieFromCartRequest struct {
+	MovieID string `json:"movieId" binding:"required,movieid"`
+	UserID  string `json:"-"`
+}
+
+type ClearMovieCartRequest struct {
+	UserID string `json:"-"`
+}
+
+type ReorderMovieCartRequest struct {
+	UserID   string   `json:"-"`
+	MovieIDs []string `json:"movieIds" binding:"required,dive,movieid"`
+}
+
+type UpdateMovieCartResponse struct {
+	Status string `json:"status"`
 }
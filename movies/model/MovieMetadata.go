@@ -0,0 +1,42 @@
+package model
+
+// MovieSummary is a search hit in the shape every metadata provider maps into.
+// ID is an IMDb id where the provider knows it, otherwise a provider-scoped id
+// that the same provider accepts for lookups.
+type MovieSummary struct {
+	ID     string
+	Title  string
+	Year   string
+	Type   string
+	Poster string
+}
+
+type MovieSearchResult struct {
+	Movies []MovieSummary
+}
+
+// MovieMetadata is the provider-neutral movie details. Fields a provider
+// doesn't know are left empty.
+type MovieMetadata struct {
+	ID         string
+	Title      string
+	Year       string
+	Rated      string
+	Released   string
+	Genre      string
+	Runtime    string
+	Director   string
+	Actors     string
+	Plot       string
+	Language   string
+	Country    string
+	Awards     string
+	Poster     string
+	Ratings    []Rating
+	Metascore  string
+	ImdbRating string
+	Type       string
+	BoxOffice  string
+	Production string
+	Website    string
+}
@@ -0,0 +1,22 @@
+package model
+
+// Problem is an RFC 7807 problem details response. Code is stable across
+// releases, so clients can tell errors apart without parsing Detail.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a field of a request that broke a validation rule. Field is
+// the field's name in the request, e.g. movieIds[1].
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
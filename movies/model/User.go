@@ -1,9 +1,14 @@
 package model
 
+import "time"
+
+// CreateUserRequest signs a user up. Country is an ISO 3166-1 alpha-2 code.
+// bcrypt only looks at the first 72 bytes of a password, hence the upper limit.
 type CreateUserRequest struct {
-	Name    string `json:"name" binding:"required"`
-	Email   string `json:"email" binding:"required"`
-	Country string `json:"country" binding:"required"`
+	Name     string `json:"name" binding:"required,max=255"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Country  string `json:"country" binding:"required,country"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
 }
 
 type CreateUserResponse struct {
@@ -11,10 +16,63 @@ type CreateUserResponse struct {
 }
 
 type User struct {
-	Name      string `json:"name" binding:"required"`
-	Email     string `json:"email" binding:"required"`
-	Country   string `json:"country" binding:"required"`
-	UserId    string `json:"userId" binding:"required"`
-	CreatedAt string `json:"createdAt" binding:"required"`
-	UpdatedAt string `json:"updatedAt" binding:"required"`
+	Name      string  `json:"name" binding:"required"`
+	Email     string  `json:"email" binding:"required"`
+	Country   string  `json:"country" binding:"required"`
+	UserId    string  `json:"userId" binding:"required"`
+	Role      string  `json:"role"`
+	CreatedAt string  `json:"createdAt" binding:"required"`
+	UpdatedAt string  `json:"updatedAt" binding:"required"`
+	DeletedAt *string `json:"deletedAt,omitempty"`
+}
+
+// UserURI is the path of a single user.
+type UserURI struct {
+	UserID string `uri:"userId" binding:"required,uuid"`
+}
+
+// UpdateUserRequest changes the fields that are set and leaves the rest.
+type UpdateUserRequest struct {
+	Name    *string `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Email   *string `json:"email,omitempty" binding:"omitempty,email,max=255"`
+	Country *string `json:"country,omitempty" binding:"omitempty,country"`
+}
+
+// ListUsersQuery filters, sorts and pages the users. Sort is a field, which
+// a leading - reverses; deleted users are left out unless IncludeDeleted is set.
+type ListUsersQuery struct {
+	Country        string    `form:"country" binding:"omitempty,country"`
+	NamePrefix     string    `form:"namePrefix" binding:"omitempty,max=255"`
+	CreatedFrom    time.Time `form:"createdFrom"`
+	CreatedTo      time.Time `form:"createdTo"`
+	IncludeDeleted bool      `form:"includeDeleted"`
+	Sort           string    `form:"sort" binding:"omitempty,oneof=name -name email -email createdAt -createdAt"`
+	Page           int       `form:"page" binding:"omitempty,min=1"`
+	PageSize       int       `form:"pageSize" binding:"omitempty,min=1,max=100"`
+}
+
+// LookupUserQuery finds a user by email.
+type LookupUserQuery struct {
+	Email string `form:"email" binding:"required,email"`
+}
+
+type DeleteUserResponse struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// ChangePasswordRequest is a user setting their own password. CurrentPassword
+// is only checked when they already have one: users who signed up before
+// passwords existed have none.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword,omitempty" binding:"omitempty,max=72"`
+	Password        string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// ResetPasswordRequest is an admin setting a new password for a user.
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type PasswordResponse struct {
+	Status string `json:"status" binding:"required"`
 }
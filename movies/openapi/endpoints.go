@@ -0,0 +1,218 @@
+package openapi
+
+import (
+	"go-movie-api/movies/client"
+	"go-movie-api/movies/middleware"
+	"go-movie-api/movies/model"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// access is who may call an endpoint.
+type access int
+
+const (
+	public access = iota
+	// anyone, but API keys need the endpoint's scope
+	optionalAuth
+	signedIn
+	adminOnly
+)
+
+// text marks endpoints that answer with plain text.
+type text string
+
+// endpoint is a route of the API. Paths are written the way they are
+// registered with gin, e.g. /users/:userId/cart.
+type endpoint struct {
+	method     string
+	path       string
+	tag        string
+	summary    string
+	access     access
+	params     any // a struct whose form and uri tags are the parameters
+	body       any
+	status     int // 200 when not set
+	response   any // nil for an empty response
+	deprecated bool
+}
+
+func endpoints() []endpoint {
+	routes := []endpoint{
+		{method: http.MethodGet, path: "/", tag: "status", summary: "Say hello", response: text("Hello, World!")},
+		{method: http.MethodGet, path: "/healthz", tag: "status", summary: "Liveness probe", response: model.HealthReport{}},
+		{method: http.MethodGet, path: "/readyz", tag: "status", summary: "Readiness probe, 503 when a critical dependency is down", response: model.HealthReport{}},
+		{method: http.MethodGet, path: "/metrics", tag: "status", summary: "Prometheus metrics", response: text("")},
+		{method: http.MethodGet, path: "/status/provider", tag: "status", summary: "Circuit breaker of the movie provider", access: adminOnly, response: client.BreakerStatus{}},
+		{method: http.MethodGet, path: "/status/cache", tag: "status", summary: "Movie cache stats", access: adminOnly, response: client.CacheStats{}},
+		{method: http.MethodGet, path: "/status/coalescing", tag: "status", summary: "Request coalescing stats", access: adminOnly, response: client.CoalescingStats{}},
+		{method: http.MethodGet, path: "/openapi.json", tag: "status", summary: "This document", response: map[string]any{}},
+		{method: http.MethodGet, path: "/docs", tag: "status", summary: "Swagger UI for this document", response: text("")},
+
+		{method: http.MethodPost, path: "/auth/login", tag: "auth", summary: "Log in with email and password", body: model.LoginRequest{}, response: model.TokenResponse{}},
+		{method: http.MethodPost, path: "/auth/refresh", tag: "auth", summary: "Swap a refresh token for new tokens", body: model.RefreshTokenRequest{}, response: model.TokenResponse{}},
+		{method: http.MethodPost, path: "/auth/logout", tag: "auth", summary: "Revoke a refresh token", body: model.RefreshTokenRequest{}, response: model.LogoutResponse{}},
+
+		{method: http.MethodPost, path: "/users/", tag: "users", summary: "Sign up", body: model.CreateUserRequest{}, response: model.CreateUserResponse{}},
+		{method: http.MethodGet, path: "/users/", tag: "users", summary: "List users", access: adminOnly, params: model.ListUsersQuery{}, response: []model.User{}, deprecated: true},
+		{method: http.MethodGet, path: "/api/v1/users", tag: "users", summary: "List users, with the total count in X-Total-Count", access: adminOnly, params: model.ListUsersQuery{}, response: []model.User{}},
+		{method: http.MethodGet, path: "/api/v1/users/lookup", tag: "users", summary: "Look a user up by email", access: adminOnly, params: model.LookupUserQuery{}, response: model.User{}},
+		{method: http.MethodGet, path: "/api/v1/users/:userId", tag: "users", summary: "Get a user", access: signedIn, params: model.UserURI{}, response: model.User{}},
+		{method: http.MethodPatch, path: "/api/v1/users/:userId", tag: "users", summary: "Update a user", access: signedIn, params: model.UserURI{}, body: model.UpdateUserRequest{}, response: model.User{}},
+		{method: http.MethodDelete, path: "/api/v1/users/:userId", tag: "users", summary: "Delete a user, who can be restored", access: signedIn, params: model.UserURI{}, response: model.DeleteUserResponse{}},
+		{method: http.MethodPost, path: "/api/v1/users/:userId/restore", tag: "users", summary: "Restore a deleted user", access: adminOnly, params: model.UserURI{}, response: model.User{}},
+		{method: http.MethodPut, path: "/api/v1/users/:userId/password", tag: "users", summary: "Change your own password", access: signedIn, params: model.UserURI{}, body: model.ChangePasswordRequest{}, response: model.PasswordResponse{}},
+		{method: http.MethodPost, path: "/api/v1/users/:userId/password-reset", tag: "users", summary: "Set a new password for a user", access: adminOnly, params: model.UserURI{}, body: model.ResetPasswordRequest{}, response: model.PasswordResponse{}},
+
+		{method: http.MethodPost, path: "/movies/search", tag: "movies", summary: "Search movies", access: optionalAuth, body: model.SearchMovieRequest{}, response: []model.Movie{}, deprecated: true},
+		{method: http.MethodPost, path: "/movies/", tag: "movies", summary: "Look a movie up", access: optionalAuth, body: model.GetMovieDetailsRequest{}, response: model.GetMovieDetailsResponse{}, deprecated: true},
+		{method: http.MethodGet, path: "/api/v1/movies", tag: "movies", summary: "Search movies", access: optionalAuth, params: model.SearchMoviesQuery{}, response: []model.Movie{}},
+		{method: http.MethodGet, path: "/api/v1/movies/:imdbId", tag: "movies", summary: "Look a movie up", access: optionalAuth, params: model.MovieURI{}, response: model.GetMovieDetailsResponse{}},
+
+		{method: http.MethodGet, path: "/api/v1/users/:userId/cart", tag: "cart", summary: "List the movies in a cart", access: signedIn, response: []model.MovieDetailsInCart{}},
+		{method: http.MethodPost, path: "/api/v1/users/:userId/cart", tag: "cart", summary: "Add a movie to a cart", access: signedIn, body: model.AddMovieToCartRequest{}, response: model.AddMovieToCartResponse{}},
+		{method: http.MethodDelete, path: "/api/v1/users/:userId/cart", tag: "cart", summary: "Empty a cart", access: signedIn, response: model.UpdateMovieCartResponse{}},
+		{method: http.MethodPut, path: "/api/v1/users/:userId/cart/order", tag: "cart", summary: "Reorder a cart", access: signedIn, body: model.ReorderMovieCartRequest{}, response: model.UpdateMovieCartResponse{}},
+		{method: http.MethodDelete, path: "/api/v1/users/:userId/cart/:imdbId", tag: "cart", summary: "Remove a movie from a cart", access: signedIn, params: model.MovieURI{}, response: model.UpdateMovieCartResponse{}},
+
+		{method: http.MethodPost, path: "/admin/api-keys", tag: "admin", summary: "Create an API key", access: adminOnly, body: model.CreateAPIKeyRequest{}, status: http.StatusCreated, response: model.CreateAPIKeyResponse{}},
+		{method: http.MethodGet, path: "/admin/api-keys", tag: "admin", summary: "List API keys", access: adminOnly, params: model.ListAPIKeysQuery{}, response: []model.APIKey{}},
+		{method: http.MethodDelete, path: "/admin/api-keys/:id", tag: "admin", summary: "Revoke an API key", access: adminOnly, response: model.RevokeAPIKeyResponse{}},
+	}
+
+	// the legacy cart routes, for the signed in user's cart and for any cart by user id
+	for _, prefix := range []string{"/movies/cart", "/users/:userId/cart"} {
+		routes = append(routes,
+			endpoint{method: http.MethodPost, path: prefix + "/add", tag: "cart", summary: "Add a movie to a cart", access: signedIn, body: model.AddMovieToCartRequest{}, response: model.AddMovieToCartResponse{}, deprecated: true},
+			endpoint{method: http.MethodPost, path: prefix + "/list", tag: "cart", summary: "List the movies in a cart", access: signedIn, response: []model.MovieDetailsInCart{}, deprecated: true},
+			endpoint{method: http.MethodPost, path: prefix + "/remove", tag: "cart", summary: "Remove a movie from a cart", access: signedIn, body: model.RemoveMovieFromCartRequest{}, response: model.UpdateMovieCartResponse{}, deprecated: true},
+			endpoint{method: http.MethodPost, path: prefix + "/clear", tag: "cart", summary: "Empty a cart", access: signedIn, response: model.UpdateMovieCartResponse{}, deprecated: true},
+			endpoint{method: http.MethodPost, path: prefix + "/reorder", tag: "cart", summary: "Reorder a cart", access: signedIn, body: model.ReorderMovieCartRequest{}, response: model.UpdateMovieCartResponse{}, deprecated: true},
+		)
+	}
+	return routes
+}
+
+// Build returns the document for every endpoint.
+func Build() *Document {
+	s := schemas{}
+	problem := s.of(reflect.TypeOf(model.Problem{}))
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "go-movie-api",
+			Description: "Search movies and keep them in a cart. Errors are RFC 7807 problem details.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas: s,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				"apiKey":     {Type: "apiKey", In: "header", Name: middleware.APIKeyHeader},
+			},
+		},
+	}
+
+	for _, e := range endpoints() {
+		path := Path(e.path)
+		op := &Operation{
+			Tags:        []string{e.tag},
+			Summary:     e.summary,
+			OperationID: operationID(e.method, path),
+			Deprecated:  e.deprecated,
+			Security:    security(e.access),
+			Parameters:  pathParameters(path, s.parameters(e.params)),
+			Responses: map[string]Response{
+				"default": {Description: "Problem details", Content: map[string]MediaType{middleware.ProblemContentType: {Schema: problem}}},
+			},
+		}
+		if e.body != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: s.of(reflect.TypeOf(e.body))}}}
+		}
+
+		status := e.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := Response{Description: http.StatusText(status)}
+		switch e.response.(type) {
+		case nil:
+		case text:
+			response.Content = map[string]MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}
+		default:
+			response.Content = map[string]MediaType{"application/json": {Schema: s.of(reflect.TypeOf(e.response))}}
+		}
+		op.Responses[strconv.Itoa(status)] = response
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(e.method)] = op
+	}
+	return doc
+}
+
+// Path turns a gin path into an OpenAPI one: /users/:userId becomes /users/{userId}.
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParameters adds the path parameters that params doesn't describe as
+// plain strings.
+func pathParameters(path string, params []Parameter) []Parameter {
+	described := map[string]bool{}
+	for _, param := range params {
+		if param.In == "path" {
+			described[param.Name] = true
+		}
+	}
+
+	var missing []Parameter
+	for _, segment := range strings.Split(path, "/") {
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+		if !described[name] {
+			missing = append(missing, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	return append(missing, params...)
+}
+
+func security(a access) []SecurityRequirement {
+	bearer := SecurityRequirement{"bearerAuth": {}}
+	apiKey := SecurityRequirement{"apiKey": {}}
+	switch a {
+	case optionalAuth:
+		return []SecurityRequirement{{}, bearer, apiKey}
+	case signedIn:
+		return []SecurityRequirement{bearer, apiKey}
+	case adminOnly:
+		return []SecurityRequirement{bearer}
+	default:
+		return nil
+	}
+}
+
+// operationID is the method and path in camel case, e.g. getApiV1MoviesImdbId.
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '.'
+	}) {
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return id
+}
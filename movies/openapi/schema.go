@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"go-movie-api/movies/constants"
+	"go-movie-api/movies/validation"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas builds schemas from Go types the way encoding/json marshals them.
+// Named structs go into the components and are referred to by name.
+type schemas map[string]*Schema
+
+func (s schemas) of(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.of(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, ok := s[t.Name()]; !ok {
+			// claim the name first, so types that refer to themselves end
+			s[t.Name()] = &Schema{}
+			*s[t.Name()] = *s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+func (s schemas) object(t reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || len(field.Index) > 1 {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			// embedded structs are flattened into this one
+			embedded := s.object(field.Type)
+			for key, property := range embedded.Properties {
+				object.Properties[key] = property
+			}
+			object.Required = append(object.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.of(field.Type)
+		if applyRules(property, field.Tag.Get("binding")) {
+			object.Required = append(object.Required, name)
+		}
+		object.Properties[name] = property
+	}
+	return object
+}
+
+// parameters returns the fields of v with a form or uri tag as query and
+// path parameters.
+func (s schemas) parameters(v any) []Parameter {
+	if v == nil {
+		return nil
+	}
+
+	var params []Parameter
+	t := reflect.TypeOf(v)
+	for _, field := range reflect.VisibleFields(t) {
+		in, name := "query", field.Tag.Get("form")
+		if uri := field.Tag.Get("uri"); uri != "" {
+			in, name = "path", uri
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := s.of(field.Type)
+		required := applyRules(schema, field.Tag.Get("binding"))
+		params = append(params, Parameter{Name: name, In: in, Required: required || in == "path", Schema: schema})
+	}
+	return params
+}
+
+// applyRules adds the validation rules of a binding tag that a schema can
+// express, and reports whether the field is required. Rules after dive
+// apply to the items of a slice.
+func applyRules(schema *Schema, binding string) (required bool) {
+	if schema.Ref != "" {
+		return strings.Contains(binding, "required")
+	}
+
+	target := schema
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = target == schema
+		case "dive":
+			if schema.Items != nil {
+				target = schema.Items
+			}
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "uuid":
+			target.Format = "uuid"
+		case "email":
+			target.Format = "email"
+		case "number":
+			target.Pattern = "^[0-9]+$"
+		case "startswith":
+			target.Pattern = "^" + param
+		case "searchtext":
+			n := constants.MinSearchLength
+			target.MinLength = &n
+		case "movieid":
+			target.Pattern = validation.MovieIDPattern
+		case "year":
+			target.Pattern = validation.YearPattern
+		case "yearrange":
+			target.Pattern = validation.YearRangePattern
+		case "movietype":
+			target.Enum = validation.MovieTypes
+		case "country", "iso3166_1_alpha2":
+			target.Pattern = validation.CountryPattern
+		case "len", "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyLength(target, name, n)
+		}
+	}
+	return required
+}
+
+func applyLength(schema *Schema, rule string, n int) {
+	if schema.Type == "array" {
+		if rule == "min" || rule == "len" {
+			schema.MinItems = &n
+		}
+		return
+	}
+	if schema.Type != "string" {
+		return
+	}
+	if rule == "min" || rule == "len" {
+		schema.MinLength = &n
+	}
+	if rule == "max" || rule == "len" {
+		schema.MaxLength = &n
+	}
+}
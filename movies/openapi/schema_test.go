@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"go-movie-api/movies/constants"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/validation"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemas(t *testing.T) {
+	s := schemas{}
+
+	t.Run("should use the json names and mark bound fields as required", func(t *testing.T) {
+		ref := s.of(reflect.TypeOf(model.SearchMovieRequest{}))
+
+		assert.Equal(t, "#/components/schemas/SearchMovieRequest", ref.Ref)
+		schema := s["SearchMovieRequest"]
+		assert.Contains(t, schema.Properties, "searchText")
+		assert.Contains(t, schema.Properties, "title")
+		assert.Equal(t, []string{"searchText"}, schema.Required)
+	})
+
+	t.Run("should use field names for fields without a json tag", func(t *testing.T) {
+		s.of(reflect.TypeOf(model.Movie{}))
+
+		assert.Contains(t, s["Movie"].Properties, "Title")
+		assert.Contains(t, s["Movie"].Properties, "ImdbID")
+	})
+
+	t.Run("should flatten embedded structs and skip hidden fields", func(t *testing.T) {
+		s.of(reflect.TypeOf(model.CreateAPIKeyResponse{}))
+		s.of(reflect.TypeOf(model.AddMovieToCartRequest{}))
+
+		assert.Contains(t, s["CreateAPIKeyResponse"].Properties, "key")
+		assert.Contains(t, s["CreateAPIKeyResponse"].Properties, "prefix")
+		assert.True(t, s["CreateAPIKeyResponse"].Properties["lastUsedAt"].Nullable)
+		assert.NotContains(t, s["AddMovieToCartRequest"].Properties, "UserID")
+	})
+
+	t.Run("should describe the validation rules", func(t *testing.T) {
+		s.of(reflect.TypeOf(model.CreateAPIKeyRequest{}))
+
+		schema := s["CreateAPIKeyRequest"]
+		assert.Equal(t, "uuid", schema.Properties["userId"].Format)
+		assert.Equal(t, []string{"movies:search", "cart:read", "cart:write", "user:read", "user:write"}, schema.Properties["scopes"].Items.Enum)
+		assert.Equal(t, 1, *schema.Properties["scopes"].MinItems)
+
+		s.of(reflect.TypeOf(model.CreateUserRequest{}))
+		assert.Equal(t, "email", s["CreateUserRequest"].Properties["email"].Format)
+		assert.Equal(t, "^[A-Z]{2}$", s["CreateUserRequest"].Properties["country"].Pattern)
+	})
+
+	t.Run("should read query and path parameters from form and uri tags", func(t *testing.T) {
+		query := s.parameters(model.SearchMoviesQuery{})
+		path := s.parameters(model.MovieURI{})
+
+		minLength := constants.MinSearchLength
+		assert.Equal(t, Parameter{Name: "q", In: "query", Required: true, Schema: &Schema{Type: "string", MinLength: &minLength}}, query[0])
+		assert.Equal(t, []string{"movie", "series", "episode"}, query[2].Schema.Enum)
+		assert.Equal(t, Parameter{Name: "imdbId", In: "path", Required: true, Schema: &Schema{Type: "string", Pattern: validation.MovieIDPattern}}, path[0])
+	})
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build()
+
+	ids := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			assert.False(t, ids[op.OperationID], "%s %s reuses the operation id %s", method, path, op.OperationID)
+			ids[op.OperationID] = true
+		}
+	}
+
+	cart := doc.Paths["/api/v1/users/{userId}/cart/{imdbId}"]["delete"]
+	assert.Equal(t, []string{"userId", "imdbId"}, []string{cart.Parameters[0].Name, cart.Parameters[1].Name})
+	assert.Equal(t, "/users/{userId}/cart/add", Path("/users/:userId/cart/add"))
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var ErrAPIKeyNotFound = apperror.NotFound("api_key_not_found", "api key not found")
+
+const apiKeyColumns = `id, user_id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+// APIKeyRepository stores API keys by the hash of the key.
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key model.APIKey, keyHash string) (created model.APIKey, err error)
+	ListAPIKeys(ctx context.Context, userId string) (keys []model.APIKey, err error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	GetActiveAPIKey(ctx context.Context, keyHash string) (key model.APIKey, err error)
+	TouchAPIKey(ctx context.Context, id string) error
+}
+
+type apiKeyRepository struct {
+	db     *sqlx.DB
+	logger *slog.Logger
+}
+
+func NewAPIKeyRepository(db *sqlx.DB, logger *slog.Logger) apiKeyRepository {
+	return apiKeyRepository{db: db, logger: logger}
+}
+
+func (ar apiKeyRepository) CreateAPIKey(ctx context.Context, key model.APIKey, keyHash string) (model.APIKey, error) {
+	row := ar.db.QueryRowContext(ctx,
+		`INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes) VALUES ($1, $2, $3, $4, $5) RETURNING `+apiKeyColumns,
+		key.UserID, key.Name, key.Prefix, keyHash, pq.StringArray(key.Scopes),
+	)
+	created, err := scanAPIKey(row)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return model.APIKey{}, ErrUserNotFound
+		}
+		ar.logger.ErrorContext(ctx, "failed to create api key", "error", err)
+		return model.APIKey{}, err
+	}
+
+	return created, nil
+}
+
+// ListAPIKeys returns the keys of a user, newest first, or everyone's when
+// userId is empty. Revoked keys are included.
+func (ar apiKeyRepository) ListAPIKeys(ctx context.Context, userId string) ([]model.APIKey, error) {
+	// user_id is compared as a uuid, so that its index is used
+	filter, args := "", []any{}
+	if userId != "" {
+		filter, args = ` WHERE user_id = $1::uuid`, append(args, userId)
+	}
+	rows, err := ar.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys`+filter+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		ar.logger.ErrorContext(ctx, "failed to list api keys", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			ar.logger.ErrorContext(ctx, "failed to read api key", "error", err)
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		ar.logger.ErrorContext(ctx, "failed to list api keys", "error", err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key that is still active.
+func (ar apiKeyRepository) RevokeAPIKey(ctx context.Context, id string) error {
+	result, err := ar.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		ar.logger.ErrorContext(ctx, "failed to revoke api key", "error", err)
+		return err
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		ar.logger.ErrorContext(ctx, "failed to revoke api key", "error", err)
+		return err
+	}
+	if revoked == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// GetActiveAPIKey looks up a key that isn't revoked and whose owner isn't
+// deleted.
+func (ar apiKeyRepository) GetActiveAPIKey(ctx context.Context, keyHash string) (model.APIKey, error) {
+	row := ar.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)`,
+		keyHash,
+	)
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.APIKey{}, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		ar.logger.ErrorContext(ctx, "failed to look up api key", "error", err)
+		return model.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// TouchAPIKey records that a key was used. It writes at most once a minute
+// per key, so busy keys don't turn every request into a write.
+func (ar apiKeyRepository) TouchAPIKey(ctx context.Context, id string) error {
+	_, err := ar.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at = NOW() WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`,
+		id,
+	)
+	if err != nil {
+		ar.logger.ErrorContext(ctx, "failed to record api key use", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (model.APIKey, error) {
+	var key model.APIKey
+	var scopes pq.StringArray
+	var lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &scopes, &key.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	key.Scopes = scopes
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
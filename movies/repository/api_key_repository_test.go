@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyRowColumns = []string{"id", "user_id", "name", "prefix", "scopes", "created_at", "last_used_at", "revoked_at"}
+
+func TestCreateAPIKey(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewAPIKeyRepository(db, logging.Discard())
+	key := model.APIKey{UserID: "123", Name: "partner", Prefix: "mvk_abcdefgh", Scopes: []string{"movies:search"}}
+	insert := regexp.QuoteMeta(`INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes)`)
+
+	t.Run("should store the key and return it", func(t *testing.T) {
+		createdAt := time.Now()
+		mock.ExpectQuery(insert).
+			WithArgs("123", "partner", "mvk_abcdefgh", "hash", pq.StringArray{"movies:search"}).
+			WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+				AddRow("key-1", "123", "partner", "mvk_abcdefgh", "{movies:search}", createdAt, nil, nil))
+
+		created, err := repo.CreateAPIKey(context.Background(), key, "hash")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "key-1", created.ID)
+		assert.Equal(t, []string{"movies:search"}, created.Scopes)
+		assert.Nil(t, created.LastUsedAt)
+	})
+
+	t.Run("should return user not found for an unknown owner", func(t *testing.T) {
+		mock.ExpectQuery(insert).WillReturnError(&pq.Error{Code: "23503"})
+
+		_, err := repo.CreateAPIKey(context.Background(), key, "hash")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestListAPIKeys(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewAPIKeyRepository(db, logging.Discard())
+	keyRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(apiKeyRowColumns).AddRow("key-1", "123", "partner", "mvk_abcdefgh", "{movies:search}", time.Now(), nil, nil)
+	}
+
+	t.Run("should list the keys of a user by their uuid", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1::uuid ORDER BY created_at DESC`)).
+			WithArgs("123").
+			WillReturnRows(keyRows())
+
+		keys, err := repo.ListAPIKeys(context.Background(), "123")
+
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should list everyone's keys without a user", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC`)).
+			WithoutArgs().
+			WillReturnRows(keyRows())
+
+		keys, err := repo.ListAPIKeys(context.Background(), "")
+
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetActiveAPIKey(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewAPIKeyRepository(db, logging.Discard())
+	query := regexp.QuoteMeta(`FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)`)
+
+	t.Run("should find an active key by its hash", func(t *testing.T) {
+		lastUsedAt := time.Now()
+		mock.ExpectQuery(query).WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+				AddRow("key-1", "123", "partner", "mvk_abcdefgh", "{cart:read,cart:write}", time.Now(), lastUsedAt, nil))
+
+		key, err := repo.GetActiveAPIKey(context.Background(), "hash")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cart:read", "cart:write"}, key.Scopes)
+		assert.Equal(t, lastUsedAt, *key.LastUsedAt)
+	})
+
+	t.Run("should return not found for a revoked or unknown key, or one of a deleted user", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("other").WillReturnRows(sqlmock.NewRows(apiKeyRowColumns))
+
+		_, err := repo.GetActiveAPIKey(context.Background(), "other")
+
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewAPIKeyRepository(db, logging.Discard())
+	revoke := regexp.QuoteMeta(`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`)
+
+	mock.ExpectExec(revoke).WithArgs("key-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.RevokeAPIKey(context.Background(), "key-1"))
+
+	mock.ExpectExec(revoke).WithArgs("key-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.RevokeAPIKey(context.Background(), "key-1"), ErrAPIKeyNotFound)
+}
+
+func TestTouchAPIKey(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewAPIKeyRepository(db, logging.Discard())
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE api_keys SET last_used_at = NOW() WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`)).
+		WithArgs("key-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.TouchAPIKey(context.Background(), "key-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
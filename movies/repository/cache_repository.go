@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// cacheRepository stores movie provider responses in Postgres so the cache is
+// shared between instances and survives restarts. It keeps at most maxEntries
+// rows, dropping the least recently read ones first.
+type cacheRepository struct {
+	db         *sqlx.DB
+	maxEntries int
+	now        func() time.Time
+	logger     *slog.Logger
+}
+
+func NewCacheRepository(db *sqlx.DB, maxEntries int, logger *slog.Logger) cacheRepository {
+	return cacheRepository{db: db, maxEntries: max(maxEntries, 1), now: time.Now, logger: logger}
+}
+
+func (cr cacheRepository) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	err := cr.db.QueryRowContext(ctx,
+		`UPDATE movie_provider_cache SET last_accessed_at = $2 WHERE cache_key = $1 AND expires_at > $2 RETURNING value`,
+		key, cr.now(),
+	).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		cr.logger.ErrorContext(ctx, "failed to read cache entry", "error", err)
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (cr cacheRepository) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	now := cr.now()
+	_, err := cr.db.ExecContext(ctx,
+		`INSERT INTO movie_provider_cache (cache_key, value, expires_at, last_accessed_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cache_key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at, last_accessed_at = EXCLUDED.last_accessed_at`,
+		key, value, now.Add(ttl), now,
+	)
+	if err != nil {
+		cr.logger.ErrorContext(ctx, "failed to write cache entry", "error", err)
+		return err
+	}
+
+	// drop expired rows and anything beyond the size limit
+	_, err = cr.db.ExecContext(ctx,
+		`DELETE FROM movie_provider_cache WHERE expires_at <= $1
+		OR cache_key IN (SELECT cache_key FROM movie_provider_cache ORDER BY last_accessed_at DESC OFFSET $2)`,
+		now, cr.maxEntries,
+	)
+	if err != nil {
+		cr.logger.ErrorContext(ctx, "failed to evict cache entries", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/logging"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheRepositoryGet(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := NewCacheRepository(db, 100, logging.Discard())
+	repo.now = func() time.Time { return now }
+	ctx := context.Background()
+	query := regexp.QuoteMeta(`UPDATE movie_provider_cache SET last_accessed_at = $2 WHERE cache_key = $1 AND expires_at > $2 RETURNING value`)
+
+	t.Run("should return a fresh entry", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("search:s=batman", now).
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow([]byte(`{"body":{}}`)))
+
+		value, found, err := repo.Get(ctx, "search:s=batman")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []byte(`{"body":{}}`), value)
+	})
+
+	t.Run("should report a miss for a missing or expired entry", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("search:s=robin", now).
+			WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		_, found, err := repo.Get(ctx, "search:s=robin")
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should return db errors", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("search:s=joker", now).
+			WillReturnError(errors.New("db down"))
+
+		_, found, err := repo.Get(ctx, "search:s=joker")
+		assert.Error(t, err)
+		assert.False(t, found)
+	})
+}
+
+func TestCacheRepositorySet(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := NewCacheRepository(db, 100, logging.Discard())
+	repo.now = func() time.Time { return now }
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO movie_provider_cache (cache_key, value, expires_at, last_accessed_at) VALUES ($1, $2, $3, $4)`)).
+		WithArgs("details:i=tt1375666", []byte(`{"body":{}}`), now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM movie_provider_cache WHERE expires_at <= $1`)).
+		WithArgs(now, 100).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Set(context.Background(), "details:i=tt1375666", []byte(`{"body":{}}`), time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/model"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueryMetrics times repository calls. The outcome is "ok", "rejected" for
+// the repository's own errors (e.g. a movie already in the cart) or "error".
+type QueryMetrics struct {
+	duration *prometheus.HistogramVec
+}
+
+func NewQueryMetrics(reg prometheus.Registerer) *QueryMetrics {
+	m := &QueryMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Repository call latency by repository, operation and outcome.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "operation", "outcome"}),
+	}
+	reg.MustRegister(m.duration)
+	return m
+}
+
+func (m *QueryMetrics) observe(repository string, operation string, start time.Time, err error) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, ErrMovieAlreadyInCart), errors.Is(err, ErrMovieNotInCart), errors.Is(err, ErrInvalidCartOrder),
+		errors.Is(err, ErrUserAlreadyExists), errors.Is(err, ErrUserNotFound), errors.Is(err, ErrAPIKeyNotFound):
+		outcome = "rejected"
+	case err != nil:
+		outcome = "error"
+	}
+	m.duration.WithLabelValues(repository, operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+type instrumentedMovieRepository struct {
+	next    MovieRespository
+	metrics *QueryMetrics
+}
+
+// NewInstrumentedMovieRepository wraps next so that every call is timed.
+func NewInstrumentedMovieRepository(next MovieRespository, metrics *QueryMetrics) MovieRespository {
+	return instrumentedMovieRepository{next: next, metrics: metrics}
+}
+
+func (ir instrumentedMovieRepository) AddToMovieCart(ctx context.Context, movie model.GetMovieDetailsResponse, userId string) (err error) {
+	defer ir.observe("AddToMovieCart", time.Now(), &err)
+	return ir.next.AddToMovieCart(ctx, movie, userId)
+}
+
+func (ir instrumentedMovieRepository) GetMoviesInCart(ctx context.Context, userId string) (movies []model.MovieDetailsInCart, err error) {
+	defer ir.observe("GetMoviesInCart", time.Now(), &err)
+	return ir.next.GetMoviesInCart(ctx, userId)
+}
+
+func (ir instrumentedMovieRepository) IsMovieInCart(ctx context.Context, userId string, imdbId string) (found bool, err error) {
+	defer ir.observe("IsMovieInCart", time.Now(), &err)
+	return ir.next.IsMovieInCart(ctx, userId, imdbId)
+}
+
+func (ir instrumentedMovieRepository) RemoveFromMovieCart(ctx context.Context, userId string, imdbId string) (err error) {
+	defer ir.observe("RemoveFromMovieCart", time.Now(), &err)
+	return ir.next.RemoveFromMovieCart(ctx, userId, imdbId)
+}
+
+func (ir instrumentedMovieRepository) ClearMovieCart(ctx context.Context, userId string) (err error) {
+	defer ir.observe("ClearMovieCart", time.Now(), &err)
+	return ir.next.ClearMovieCart(ctx, userId)
+}
+
+func (ir instrumentedMovieRepository) ReorderMovieCart(ctx context.Context, userId string, imdbIds []string) (err error) {
+	defer ir.observe("ReorderMovieCart", time.Now(), &err)
+	return ir.next.ReorderMovieCart(ctx, userId, imdbIds)
+}
+
+func (ir instrumentedMovieRepository) observe(operation string, start time.Time, err *error) {
+	ir.metrics.observe("movies", operation, start, *err)
+}
+
+type instrumentedUserRepository struct {
+	next    UserRespository
+	metrics *QueryMetrics
+}
+
+// NewInstrumentedUserRepository wraps next so that every call is timed.
+func NewInstrumentedUserRepository(next UserRespository, metrics *QueryMetrics) UserRespository {
+	return instrumentedUserRepository{next: next, metrics: metrics}
+}
+
+func (ir instrumentedUserRepository) CreateUser(ctx context.Context, user model.CreateUserRequest, passwordHash string) (err error) {
+	defer ir.observe("CreateUser", time.Now(), &err)
+	return ir.next.CreateUser(ctx, user, passwordHash)
+}
+
+func (ir instrumentedUserRepository) ListUsers(ctx context.Context, query model.ListUsersQuery) (users []model.User, total int, err error) {
+	defer ir.observe("ListUsers", time.Now(), &err)
+	return ir.next.ListUsers(ctx, query)
+}
+
+func (ir instrumentedUserRepository) GetUser(ctx context.Context, userId string) (user model.User, err error) {
+	defer ir.observe("GetUser", time.Now(), &err)
+	return ir.next.GetUser(ctx, userId)
+}
+
+func (ir instrumentedUserRepository) GetUserByEmail(ctx context.Context, email string) (user model.User, err error) {
+	defer ir.observe("GetUserByEmail", time.Now(), &err)
+	return ir.next.GetUserByEmail(ctx, email)
+}
+
+func (ir instrumentedUserRepository) UpdateUser(ctx context.Context, userId string, update model.UpdateUserRequest) (user model.User, err error) {
+	defer ir.observe("UpdateUser", time.Now(), &err)
+	return ir.next.UpdateUser(ctx, userId, update)
+}
+
+func (ir instrumentedUserRepository) DeleteUser(ctx context.Context, userId string) (err error) {
+	defer ir.observe("DeleteUser", time.Now(), &err)
+	return ir.next.DeleteUser(ctx, userId)
+}
+
+func (ir instrumentedUserRepository) RestoreUser(ctx context.Context, userId string) (user model.User, err error) {
+	defer ir.observe("RestoreUser", time.Now(), &err)
+	return ir.next.RestoreUser(ctx, userId)
+}
+
+func (ir instrumentedUserRepository) GetUserCredentials(ctx context.Context, email string) (credentials model.UserCredentials, err error) {
+	defer ir.observe("GetUserCredentials", time.Now(), &err)
+	return ir.next.GetUserCredentials(ctx, email)
+}
+
+func (ir instrumentedUserRepository) SetUserPassword(ctx context.Context, userId string, passwordHash string) (err error) {
+	defer ir.observe("SetUserPassword", time.Now(), &err)
+	return ir.next.SetUserPassword(ctx, userId, passwordHash)
+}
+
+func (ir instrumentedUserRepository) GetUserRole(ctx context.Context, userId string) (role string, err error) {
+	defer ir.observe("GetUserRole", time.Now(), &err)
+	return ir.next.GetUserRole(ctx, userId)
+}
+
+func (ir instrumentedUserRepository) PromoteFirstAdmin(ctx context.Context, email string) (promoted bool, err error) {
+	defer ir.observe("PromoteFirstAdmin", time.Now(), &err)
+	return ir.next.PromoteFirstAdmin(ctx, email)
+}
+
+func (ir instrumentedUserRepository) observe(operation string, start time.Time, err *error) {
+	ir.metrics.observe("users", operation, start, *err)
+}
+
+type instrumentedTokenRepository struct {
+	next    TokenRepository
+	metrics *QueryMetrics
+}
+
+// NewInstrumentedTokenRepository wraps next so that every call is timed.
+func NewInstrumentedTokenRepository(next TokenRepository, metrics *QueryMetrics) TokenRepository {
+	return instrumentedTokenRepository{next: next, metrics: metrics}
+}
+
+func (ir instrumentedTokenRepository) SaveRefreshToken(ctx context.Context, id string, userId string, expiresAt time.Time) (err error) {
+	defer ir.observe("SaveRefreshToken", time.Now(), &err)
+	return ir.next.SaveRefreshToken(ctx, id, userId, expiresAt)
+}
+
+func (ir instrumentedTokenRepository) RevokeRefreshToken(ctx context.Context, id string, userId string) (revoked bool, err error) {
+	defer ir.observe("RevokeRefreshToken", time.Now(), &err)
+	return ir.next.RevokeRefreshToken(ctx, id, userId)
+}
+
+func (ir instrumentedTokenRepository) observe(operation string, start time.Time, err *error) {
+	ir.metrics.observe("tokens", operation, start, *err)
+}
+
+type instrumentedAPIKeyRepository struct {
+	next    APIKeyRepository
+	metrics *QueryMetrics
+}
+
+// NewInstrumentedAPIKeyRepository wraps next so that every call is timed.
+func NewInstrumentedAPIKeyRepository(next APIKeyRepository, metrics *QueryMetrics) APIKeyRepository {
+	return instrumentedAPIKeyRepository{next: next, metrics: metrics}
+}
+
+func (ir instrumentedAPIKeyRepository) CreateAPIKey(ctx context.Context, key model.APIKey, keyHash string) (created model.APIKey, err error) {
+	defer ir.observe("CreateAPIKey", time.Now(), &err)
+	return ir.next.CreateAPIKey(ctx, key, keyHash)
+}
+
+func (ir instrumentedAPIKeyRepository) ListAPIKeys(ctx context.Context, userId string) (keys []model.APIKey, err error) {
+	defer ir.observe("ListAPIKeys", time.Now(), &err)
+	return ir.next.ListAPIKeys(ctx, userId)
+}
+
+func (ir instrumentedAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	defer ir.observe("RevokeAPIKey", time.Now(), &err)
+	return ir.next.RevokeAPIKey(ctx, id)
+}
+
+func (ir instrumentedAPIKeyRepository) GetActiveAPIKey(ctx context.Context, keyHash string) (key model.APIKey, err error) {
+	defer ir.observe("GetActiveAPIKey", time.Now(), &err)
+	return ir.next.GetActiveAPIKey(ctx, keyHash)
+}
+
+func (ir instrumentedAPIKeyRepository) TouchAPIKey(ctx context.Context, id string) (err error) {
+	defer ir.observe("TouchAPIKey", time.Now(), &err)
+	return ir.next.TouchAPIKey(ctx, id)
+}
+
+func (ir instrumentedAPIKeyRepository) observe(operation string, start time.Time, err *error) {
+	ir.metrics.observe("api_keys", operation, start, *err)
+}
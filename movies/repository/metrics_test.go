@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/mock"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func sampleCount(t *testing.T, histogram *prometheus.HistogramVec, labels ...string) uint64 {
+	var metric dto.Metric
+	assert.NoError(t, histogram.WithLabelValues(labels...).(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestInstrumentedMovieRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mock.NewMockMovieRespository(ctrl)
+	metrics := NewQueryMetrics(prometheus.NewRegistry())
+	repo := NewInstrumentedMovieRepository(next, metrics)
+	ctx := context.Background()
+
+	next.EXPECT().ClearMovieCart(ctx, "123").Return(nil)
+	next.EXPECT().RemoveFromMovieCart(ctx, "123", "tt0").Return(ErrMovieNotInCart)
+	next.EXPECT().IsMovieInCart(ctx, "123", "tt1375666").Return(false, errors.New("connection reset"))
+
+	assert.NoError(t, repo.ClearMovieCart(ctx, "123"))
+	assert.ErrorIs(t, repo.RemoveFromMovieCart(ctx, "123", "tt0"), ErrMovieNotInCart)
+	_, err := repo.IsMovieInCart(ctx, "123", "tt1375666")
+	assert.Error(t, err)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics.duration))
+	for _, labels := range [][]string{
+		{"movies", "ClearMovieCart", "ok"},
+		{"movies", "RemoveFromMovieCart", "rejected"},
+		{"movies", "IsMovieInCart", "error"},
+	} {
+		assert.Equal(t, uint64(1), sampleCount(t, metrics.duration, labels...), labels)
+	}
+}
+
+func TestInstrumentedUserRepository(t *testing.T) {
+	db, sqlMock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	metrics := NewQueryMetrics(prometheus.NewRegistry())
+	repo := NewInstrumentedUserRepository(NewUserRepository(db, logging.Discard()), metrics)
+
+	sqlMock.ExpectQuery("SELECT id, user_name").WithArgs("123").WillReturnError(errors.New("connection reset"))
+
+	_, err := repo.GetUser(context.Background(), "123")
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.duration, "db_query_duration_seconds"))
+}
@@ -1,57 +1,87 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"go-movie-api/movies/apperror"
 	"go-movie-api/movies/model"
-	"log"
+	"log/slog"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
+// cartUserMovieConstraint is the (user_id, imdb_id) unique key on movies_cart.
+const cartUserMovieConstraint = "uq_movies_cart_user_imdb"
+
+// cartUserPositionConstraint is the deferrable (user_id, position) unique key
+// on movies_cart.
+const cartUserPositionConstraint = "uq_movies_cart_user_position"
+
+// addToCartAttempts bounds the retries of an add that lost the race for the
+// end of the cart to another add.
+const addToCartAttempts = 3
+
+var (
+	ErrMovieAlreadyInCart = apperror.Conflict("movie_already_in_cart", "movie already added to the cart")
+	ErrMovieNotInCart     = apperror.NotFound("movie_not_in_cart", "movie is not in the cart")
+	ErrInvalidCartOrder   = apperror.Validation("invalid_cart_order", "movie ids must list every movie in the cart exactly once")
+)
+
 type MovieRespository interface {
-	AddToMovieCart(movie model.GetMovieDetailsResponse, userId string) error
-	GetMoviesInCart(userId string) (movies []model.MovieDetailsInCart, err error)
+	AddToMovieCart(ctx context.Context, movie model.GetMovieDetailsResponse, userId string) error
+	GetMoviesInCart(ctx context.Context, userId string) (movies []model.MovieDetailsInCart, err error)
+	IsMovieInCart(ctx context.Context, userId string, imdbId string) (bool, error)
+	RemoveFromMovieCart(ctx context.Context, userId string, imdbId string) error
+	ClearMovieCart(ctx context.Context, userId string) error
+	ReorderMovieCart(ctx context.Context, userId string, imdbIds []string) error
 }
 
 type movieRespository struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	logger *slog.Logger
 }
 
-func NewMovieRepository(db *sqlx.DB) movieRespository {
-	return movieRespository{db: db}
+func NewMovieRepository(db *sqlx.DB, logger *slog.Logger) movieRespository {
+	return movieRespository{db: db, logger: logger}
 }
 
-func (mr movieRespository) AddToMovieCart(movie model.GetMovieDetailsResponse, userId string) error {
-	_, err := mr.db.Exec(
-		"INSERT INTO movies_cart (user_id, title, imdb_id, year, genre, actors, type, poster) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
-		userId, movie.Title, movie.ImdbID, movie.Year, movie.Genre, movie.Actors, movie.Type, movie.Poster,
-	)
-	if err != nil {
+func (mr movieRespository) AddToMovieCart(ctx context.Context, movie model.GetMovieDetailsResponse, userId string) error {
+	for attempt := 1; ; attempt++ {
+		// new movies go to the end of the user's cart
+		_, err := mr.db.ExecContext(ctx,
+			`INSERT INTO movies_cart (user_id, title, imdb_id, year, genre, actors, type, poster, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, (SELECT COALESCE(MAX(position), 0) + 1 FROM movies_cart WHERE user_id = $1))`,
+			userId, movie.Title, movie.ImdbID, movie.Year, movie.Genre, movie.Actors, movie.Type, movie.Poster,
+		)
+		if err == nil {
+			return nil
+		}
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
-			return errors.New("movie already added to the cart")
+			if pqErr.Constraint == cartUserMovieConstraint {
+				return ErrMovieAlreadyInCart
+			}
+			if pqErr.Constraint == cartUserPositionConstraint && attempt < addToCartAttempts {
+				continue
+			}
 		}
-		log.Println(err)
+		mr.logger.ErrorContext(ctx, "failed to add movie to cart", "error", err)
 		return err
 	}
-
-	return nil
 }
 
-func (mr movieRespository) GetMoviesInCart(userId string) (result []model.MovieDetailsInCart, err error) {
-	rows, err := mr.db.Query(`SELECT title, imdb_id, year, genre, actors, type, poster FROM movies_cart WHERE user_id = $1`, userId)
+func (mr movieRespository) GetMoviesInCart(ctx context.Context, userId string) (result []model.MovieDetailsInCart, err error) {
+	rows, err := mr.db.QueryContext(ctx, `SELECT id, title, imdb_id, year, genre, actors, type, poster, position FROM movies_cart WHERE user_id = $1 ORDER BY position`, userId)
 	if err != nil {
-		log.Println(err)
+		mr.logger.ErrorContext(ctx, "failed to list cart", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
 
 	var movies []model.MovieDetailsInCart
 	for rows.Next() {
-		log.Println("Scan row:", err)
 		var movie model.MovieDetailsInCart
-		if err := rows.Scan(&movie.Title, &movie.ImdbID, &movie.Year, &movie.Genre, &movie.Type, &movie.Actors, &movie.Poster); err != nil {
-			log.Println("Scan error:", err)
+		if err := rows.Scan(&movie.ID, &movie.Title, &movie.ImdbID, &movie.Year, &movie.Genre, &movie.Actors, &movie.Type, &movie.Poster, &movie.Position); err != nil {
+			mr.logger.WarnContext(ctx, "skipping unreadable cart row", "error", err)
 			continue
 		}
 		movies = append(movies, movie)
@@ -59,3 +89,107 @@ func (mr movieRespository) GetMoviesInCart(userId string) (result []model.MovieD
 
 	return movies, nil
 }
+
+func (mr movieRespository) IsMovieInCart(ctx context.Context, userId string, imdbId string) (bool, error) {
+	var exists bool
+	err := mr.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM movies_cart WHERE user_id = $1 AND imdb_id = $2)`,
+		userId, imdbId,
+	).Scan(&exists)
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to check cart", "error", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (mr movieRespository) RemoveFromMovieCart(ctx context.Context, userId string, imdbId string) error {
+	result, err := mr.db.ExecContext(ctx, `DELETE FROM movies_cart WHERE user_id = $1 AND imdb_id = $2`, userId, imdbId)
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to remove movie from cart", "error", err)
+		return err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to remove movie from cart", "error", err)
+		return err
+	}
+
+	if removed == 0 {
+		return ErrMovieNotInCart
+	}
+
+	return nil
+}
+
+func (mr movieRespository) ClearMovieCart(ctx context.Context, userId string) error {
+	if _, err := mr.db.ExecContext(ctx, `DELETE FROM movies_cart WHERE user_id = $1`, userId); err != nil {
+		mr.logger.ErrorContext(ctx, "failed to clear cart", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReorderMovieCart sets the position of every movie in the user's cart to its
+// index in imdbIds. imdbIds has to contain each movie in the cart exactly once.
+func (mr movieRespository) ReorderMovieCart(ctx context.Context, userId string, imdbIds []string) (err error) {
+	tx, err := mr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to reorder cart", "error", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// lock the cart rows so a concurrent add or remove can't slip in between the check and the update
+	var inCart []string
+	if err = tx.SelectContext(ctx, &inCart, `SELECT imdb_id FROM movies_cart WHERE user_id = $1 FOR UPDATE`, userId); err != nil {
+		mr.logger.ErrorContext(ctx, "failed to reorder cart", "error", err)
+		return err
+	}
+
+	if !isPermutation(inCart, imdbIds) {
+		return ErrInvalidCartOrder
+	}
+
+	// positions are swapped one row at a time, so they are only unique again at commit
+	if _, err = tx.ExecContext(ctx, `SET CONSTRAINTS `+cartUserPositionConstraint+` DEFERRED`); err != nil {
+		mr.logger.ErrorContext(ctx, "failed to reorder cart", "error", err)
+		return err
+	}
+
+	for i, imdbId := range imdbIds {
+		if _, err = tx.ExecContext(ctx, `UPDATE movies_cart SET position = $1 WHERE user_id = $2 AND imdb_id = $3`, i+1, userId, imdbId); err != nil {
+			mr.logger.ErrorContext(ctx, "failed to reorder cart", "error", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func isPermutation(existing []string, ordered []string) bool {
+	if len(existing) != len(ordered) {
+		return false
+	}
+
+	remaining := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		remaining[id] = true
+	}
+
+	for _, id := range ordered {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+
+	return true
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"go-movie-api/movies/logging"
 	"go-movie-api/movies/model"
 	"regexp"
 	"testing"
@@ -26,7 +28,8 @@ func TestAddToMovieCartSuccess(t *testing.T) {
 	db, mock, closeDb := setupMockDB(t)
 	defer closeDb()
 
-	repo := NewMovieRepository(db)
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
 
 	userId := "456"
 	movie := model.GetMovieDetailsResponse{
@@ -39,11 +42,11 @@ func TestAddToMovieCartSuccess(t *testing.T) {
 		Poster: "N/A",
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO movies_cart (user_id, title, imdb_id, year, genre, actors, type, poster) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`)).
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO movies_cart (user_id, title, imdb_id, year, genre, actors, type, poster, position)`)).
 		WithArgs(userId, movie.Title, movie.ImdbID, movie.Year, movie.Genre, movie.Actors, movie.Type, movie.Poster).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := repo.AddToMovieCart(movie, userId)
+	err := repo.AddToMovieCart(ctx, movie, userId)
 	assert.NoError(t, err)
 }
 
@@ -51,7 +54,8 @@ func TestAddToMovieCartDuplicate(t *testing.T) {
 	db, mock, closeDb := setupMockDB(t)
 	defer closeDb()
 
-	repo := NewMovieRepository(db)
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
 
 	userId := "456"
 	movie := model.GetMovieDetailsResponse{
@@ -64,20 +68,70 @@ func TestAddToMovieCartDuplicate(t *testing.T) {
 		Poster: "N/A",
 	}
 
-	pqErr := &pq.Error{Code: "23505"} // unique_violation
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO movies_cart (user_id, title, imdb_id, year, genre, actors, type, poster) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`)).
+	pqErr := &pq.Error{Code: "23505", Constraint: "uq_movies_cart_user_imdb"} // unique_violation
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO movies_cart (user_id, title, imdb_id, year, genre, actors, type, poster, position)`)).
 		WithArgs(userId, movie.Title, movie.ImdbID, movie.Year, movie.Genre, movie.Actors, movie.Type, movie.Poster).
 		WillReturnError(pqErr)
 
-	err := repo.AddToMovieCart(movie, userId)
+	err := repo.AddToMovieCart(ctx, movie, userId)
+	assert.ErrorIs(t, err, ErrMovieAlreadyInCart)
 	assert.EqualError(t, err, "movie already added to the cart")
 }
 
+func TestAddToMovieCartPositionRace(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
+
+	movie := model.GetMovieDetailsResponse{Title: "Inception", ImdbID: "tt1375666"}
+	insert := regexp.QuoteMeta(`INSERT INTO movies_cart`)
+	positionTaken := &pq.Error{Code: "23505", Constraint: "uq_movies_cart_user_position"}
+
+	t.Run("should retry when another add took the last position", func(t *testing.T) {
+		mock.ExpectExec(insert).WillReturnError(positionTaken)
+		mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, repo.AddToMovieCart(ctx, movie, "456"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should give up after a few attempts", func(t *testing.T) {
+		for range addToCartAttempts {
+			mock.ExpectExec(insert).WillReturnError(positionTaken)
+		}
+
+		err := repo.AddToMovieCart(ctx, movie, "456")
+		assert.ErrorIs(t, err, positionTaken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAddToMovieCartOtherUniqueViolation(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
+
+	movie := model.GetMovieDetailsResponse{Title: "Inception", ImdbID: "tt1375666"}
+
+	pqErr := &pq.Error{Code: "23505", Constraint: "pk_movies_cart"}
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO movies_cart`)).
+		WillReturnError(pqErr)
+
+	err := repo.AddToMovieCart(ctx, movie, "456")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrMovieAlreadyInCart)
+}
+
 func TestGetMoviesInCartSuccess(t *testing.T) {
 	db, mock, closeDb := setupMockDB(t)
 	defer closeDb()
 
-	repo := NewMovieRepository(db)
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
 
 	movie := model.GetMovieDetailsResponse{
 		Title:  "Inception",
@@ -89,8 +143,9 @@ func TestGetMoviesInCartSuccess(t *testing.T) {
 		Poster: "N/A",
 	}
 	userId := "123"
-	rows := sqlmock.NewRows([]string{"title", "imdb_id", "year", "genre", "actors", "type", "poster"}).
+	rows := sqlmock.NewRows([]string{"id", "title", "imdb_id", "year", "genre", "actors", "type", "poster", "position"}).
 		AddRow(
+			"c0ffee00-0000-0000-0000-000000000001",
 			movie.Title,
 			movie.ImdbID,
 			movie.Year,
@@ -98,13 +153,138 @@ func TestGetMoviesInCartSuccess(t *testing.T) {
 			movie.Actors,
 			movie.Type,
 			movie.Poster,
+			1,
 		)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT title, imdb_id, year, genre, actors, type, poster FROM movies_cart WHERE user_id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, imdb_id, year, genre, actors, type, poster, position FROM movies_cart WHERE user_id = $1 ORDER BY position")).
 		WillReturnRows(rows)
 
-	result, err := repo.GetMoviesInCart(userId)
+	result, err := repo.GetMoviesInCart(ctx, userId)
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
+	assert.Equal(t, "c0ffee00-0000-0000-0000-000000000001", result[0].ID)
 	assert.Equal(t, movie.Title, result[0].Title)
+	assert.Equal(t, movie.Actors, result[0].Actors)
+	assert.Equal(t, movie.Type, result[0].Type)
+	assert.Equal(t, 1, result[0].Position)
+}
+
+func TestIsMovieInCart(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
+
+	t.Run("should report a movie already in the user's cart", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM movies_cart WHERE user_id = $1 AND imdb_id = $2)`)).
+			WithArgs("123", "tt1375666").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		inCart, err := repo.IsMovieInCart(ctx, "123", "tt1375666")
+		assert.NoError(t, err)
+		assert.True(t, inCart)
+	})
+
+	t.Run("should not report a movie that only another user has added", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM movies_cart WHERE user_id = $1 AND imdb_id = $2)`)).
+			WithArgs("456", "tt1375666").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		inCart, err := repo.IsMovieInCart(ctx, "456", "tt1375666")
+		assert.NoError(t, err)
+		assert.False(t, inCart)
+	})
+}
+
+func TestRemoveFromMovieCart(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
+
+	t.Run("should delete the movie from the user's cart", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM movies_cart WHERE user_id = $1 AND imdb_id = $2`)).
+			WithArgs("123", "tt1375666").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.RemoveFromMovieCart(ctx, "123", "tt1375666")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return not in cart error when nothing was deleted", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM movies_cart WHERE user_id = $1 AND imdb_id = $2`)).
+			WithArgs("123", "tt0000001").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RemoveFromMovieCart(ctx, "123", "tt0000001")
+		assert.ErrorIs(t, err, ErrMovieNotInCart)
+	})
+}
+
+func TestClearMovieCart(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM movies_cart WHERE user_id = $1`)).
+		WithArgs("123").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := repo.ClearMovieCart(ctx, "123")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderMovieCart(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewMovieRepository(db, logging.Discard())
+	ctx := context.Background()
+	selectCart := regexp.QuoteMeta(`SELECT imdb_id FROM movies_cart WHERE user_id = $1 FOR UPDATE`)
+	updatePosition := regexp.QuoteMeta(`UPDATE movies_cart SET position = $1 WHERE user_id = $2 AND imdb_id = $3`)
+	deferPositions := regexp.QuoteMeta(`SET CONSTRAINTS uq_movies_cart_user_position DEFERRED`)
+
+	t.Run("should set positions in the given order", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectCart).
+			WithArgs("123").
+			WillReturnRows(sqlmock.NewRows([]string{"imdb_id"}).AddRow("tt1375666").AddRow("tt0816692"))
+		mock.ExpectExec(deferPositions).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(updatePosition).WithArgs(1, "123", "tt0816692").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(updatePosition).WithArgs(2, "123", "tt1375666").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.ReorderMovieCart(ctx, "123", []string{"tt0816692", "tt1375666"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should reject an order that leaves out a movie", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectCart).
+			WithArgs("123").
+			WillReturnRows(sqlmock.NewRows([]string{"imdb_id"}).AddRow("tt1375666").AddRow("tt0816692"))
+		mock.ExpectRollback()
+
+		err := repo.ReorderMovieCart(ctx, "123", []string{"tt0816692"})
+		assert.ErrorIs(t, err, ErrInvalidCartOrder)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should reject an order that repeats a movie", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectCart).
+			WithArgs("123").
+			WillReturnRows(sqlmock.NewRows([]string{"imdb_id"}).AddRow("tt1375666").AddRow("tt0816692"))
+		mock.ExpectRollback()
+
+		err := repo.ReorderMovieCart(ctx, "123", []string{"tt0816692", "tt0816692"})
+		assert.ErrorIs(t, err, ErrInvalidCartOrder)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
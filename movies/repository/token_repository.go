@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TokenRepository keeps track of the refresh tokens handed out, so they can
+// be used once and revoked at logout.
+type TokenRepository interface {
+	SaveRefreshToken(ctx context.Context, id string, userId string, expiresAt time.Time) error
+	RevokeRefreshToken(ctx context.Context, id string, userId string) (revoked bool, err error)
+}
+
+type tokenRepository struct {
+	db     *sqlx.DB
+	logger *slog.Logger
+}
+
+func NewTokenRepository(db *sqlx.DB, logger *slog.Logger) tokenRepository {
+	return tokenRepository{db: db, logger: logger}
+}
+
+func (tr tokenRepository) SaveRefreshToken(ctx context.Context, id string, userId string, expiresAt time.Time) error {
+	_, err := tr.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (id, user_id, expires_at) VALUES ($1, $2, $3)`,
+		id, userId, expiresAt,
+	)
+	if err != nil {
+		tr.logger.ErrorContext(ctx, "failed to save refresh token", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeRefreshToken revokes a token that is still active and reports whether
+// it was. Only one of two concurrent calls for the same token gets true.
+func (tr tokenRepository) RevokeRefreshToken(ctx context.Context, id string, userId string) (bool, error) {
+	result, err := tr.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`,
+		id, userId,
+	)
+	if err != nil {
+		tr.logger.ErrorContext(ctx, "failed to revoke refresh token", "error", err)
+		return false, err
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		tr.logger.ErrorContext(ctx, "failed to revoke refresh token", "error", err)
+		return false, err
+	}
+
+	return revoked == 1, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/movies/logging"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveRefreshToken(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewTokenRepository(db, logging.Discard())
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO refresh_tokens (id, user_id, expires_at)`)).
+		WithArgs("token-id", "123", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.SaveRefreshToken(context.Background(), "token-id", "123", expiresAt)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewTokenRepository(db, logging.Discard())
+	revoke := regexp.QuoteMeta(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`)
+
+	t.Run("should report an active token as revoked", func(t *testing.T) {
+		mock.ExpectExec(revoke).WithArgs("token-id", "123").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		revoked, err := repo.RevokeRefreshToken(context.Background(), "token-id", "123")
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("should not report a token that was already revoked", func(t *testing.T) {
+		mock.ExpectExec(revoke).WithArgs("token-id", "123").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		revoked, err := repo.RevokeRefreshToken(context.Background(), "token-id", "123")
+
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startQuerySpan starts a client span for a repository call, named
+// <repository>.<operation>.
+func startQuerySpan(ctx context.Context, tracer trace.Tracer, repository string, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation))
+	return tracer.Start(ctx, repository+"."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+type tracedMovieRepository struct {
+	next   MovieRespository
+	tracer trace.Tracer
+}
+
+// NewTracedMovieRepository wraps next so that every call gets a span.
+func NewTracedMovieRepository(next MovieRespository, provider trace.TracerProvider) MovieRespository {
+	return tracedMovieRepository{next: next, tracer: tracing.Tracer(provider)}
+}
+
+func (tr tracedMovieRepository) AddToMovieCart(ctx context.Context, movie model.GetMovieDetailsResponse, userId string) (err error) {
+	ctx, span := tr.start(ctx, "AddToMovieCart", attribute.String("movie.id", movie.ImdbID))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.AddToMovieCart(ctx, movie, userId)
+}
+
+func (tr tracedMovieRepository) GetMoviesInCart(ctx context.Context, userId string) (movies []model.MovieDetailsInCart, err error) {
+	ctx, span := tr.start(ctx, "GetMoviesInCart")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.GetMoviesInCart(ctx, userId)
+}
+
+func (tr tracedMovieRepository) IsMovieInCart(ctx context.Context, userId string, imdbId string) (found bool, err error) {
+	ctx, span := tr.start(ctx, "IsMovieInCart", attribute.String("movie.id", imdbId))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.IsMovieInCart(ctx, userId, imdbId)
+}
+
+func (tr tracedMovieRepository) RemoveFromMovieCart(ctx context.Context, userId string, imdbId string) (err error) {
+	ctx, span := tr.start(ctx, "RemoveFromMovieCart", attribute.String("movie.id", imdbId))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.RemoveFromMovieCart(ctx, userId, imdbId)
+}
+
+func (tr tracedMovieRepository) ClearMovieCart(ctx context.Context, userId string) (err error) {
+	ctx, span := tr.start(ctx, "ClearMovieCart")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.ClearMovieCart(ctx, userId)
+}
+
+func (tr tracedMovieRepository) ReorderMovieCart(ctx context.Context, userId string, imdbIds []string) (err error) {
+	ctx, span := tr.start(ctx, "ReorderMovieCart", attribute.Int("movie.count", len(imdbIds)))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.ReorderMovieCart(ctx, userId, imdbIds)
+}
+
+func (tr tracedMovieRepository) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return startQuerySpan(ctx, tr.tracer, "movieRepository", operation, attrs...)
+}
+
+type tracedUserRepository struct {
+	next   UserRespository
+	tracer trace.Tracer
+}
+
+// NewTracedUserRepository wraps next so that every call gets a span.
+func NewTracedUserRepository(next UserRespository, provider trace.TracerProvider) UserRespository {
+	return tracedUserRepository{next: next, tracer: tracing.Tracer(provider)}
+}
+
+func (tr tracedUserRepository) CreateUser(ctx context.Context, user model.CreateUserRequest, passwordHash string) (err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "CreateUser")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.CreateUser(ctx, user, passwordHash)
+}
+
+func (tr tracedUserRepository) ListUsers(ctx context.Context, query model.ListUsersQuery) (users []model.User, total int, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "ListUsers")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.ListUsers(ctx, query)
+}
+
+func (tr tracedUserRepository) GetUser(ctx context.Context, userId string) (user model.User, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "GetUser", attribute.String("user.id", userId))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.GetUser(ctx, userId)
+}
+
+func (tr tracedUserRepository) GetUserByEmail(ctx context.Context, email string) (user model.User, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "GetUserByEmail")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.GetUserByEmail(ctx, email)
+}
+
+func (tr tracedUserRepository) UpdateUser(ctx context.Context, userId string, update model.UpdateUserRequest) (user model.User, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "UpdateUser", attribute.String("user.id", userId))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.UpdateUser(ctx, userId, update)
+}
+
+func (tr tracedUserRepository) DeleteUser(ctx context.Context, userId string) (err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "DeleteUser", attribute.String("user.id", userId))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.DeleteUser(ctx, userId)
+}
+
+func (tr tracedUserRepository) RestoreUser(ctx context.Context, userId string) (user model.User, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "RestoreUser", attribute.String("user.id", userId))
+	defer func() { tracing.End(span, err) }()
+	return tr.next.RestoreUser(ctx, userId)
+}
+
+func (tr tracedUserRepository) GetUserCredentials(ctx context.Context, email string) (credentials model.UserCredentials, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "GetUserCredentials")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.GetUserCredentials(ctx, email)
+}
+
+func (tr tracedUserRepository) SetUserPassword(ctx context.Context, userId string, passwordHash string) (err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "SetUserPassword")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.SetUserPassword(ctx, userId, passwordHash)
+}
+
+func (tr tracedUserRepository) GetUserRole(ctx context.Context, userId string) (role string, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "GetUserRole")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.GetUserRole(ctx, userId)
+}
+
+func (tr tracedUserRepository) PromoteFirstAdmin(ctx context.Context, email string) (promoted bool, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "userRepository", "PromoteFirstAdmin")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.PromoteFirstAdmin(ctx, email)
+}
+
+type tracedTokenRepository struct {
+	next   TokenRepository
+	tracer trace.Tracer
+}
+
+// NewTracedTokenRepository wraps next so that every call gets a span.
+func NewTracedTokenRepository(next TokenRepository, provider trace.TracerProvider) TokenRepository {
+	return tracedTokenRepository{next: next, tracer: tracing.Tracer(provider)}
+}
+
+func (tr tracedTokenRepository) SaveRefreshToken(ctx context.Context, id string, userId string, expiresAt time.Time) (err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "tokenRepository", "SaveRefreshToken")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.SaveRefreshToken(ctx, id, userId, expiresAt)
+}
+
+func (tr tracedTokenRepository) RevokeRefreshToken(ctx context.Context, id string, userId string) (revoked bool, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "tokenRepository", "RevokeRefreshToken")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.RevokeRefreshToken(ctx, id, userId)
+}
+
+type tracedAPIKeyRepository struct {
+	next   APIKeyRepository
+	tracer trace.Tracer
+}
+
+// NewTracedAPIKeyRepository wraps next so that every call gets a span.
+func NewTracedAPIKeyRepository(next APIKeyRepository, provider trace.TracerProvider) APIKeyRepository {
+	return tracedAPIKeyRepository{next: next, tracer: tracing.Tracer(provider)}
+}
+
+func (tr tracedAPIKeyRepository) CreateAPIKey(ctx context.Context, key model.APIKey, keyHash string) (created model.APIKey, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "apiKeyRepository", "CreateAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.CreateAPIKey(ctx, key, keyHash)
+}
+
+func (tr tracedAPIKeyRepository) ListAPIKeys(ctx context.Context, userId string) (keys []model.APIKey, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "apiKeyRepository", "ListAPIKeys")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.ListAPIKeys(ctx, userId)
+}
+
+func (tr tracedAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "apiKeyRepository", "RevokeAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.RevokeAPIKey(ctx, id)
+}
+
+func (tr tracedAPIKeyRepository) GetActiveAPIKey(ctx context.Context, keyHash string) (key model.APIKey, err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "apiKeyRepository", "GetActiveAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.GetActiveAPIKey(ctx, keyHash)
+}
+
+func (tr tracedAPIKeyRepository) TouchAPIKey(ctx context.Context, id string) (err error) {
+	ctx, span := startQuerySpan(ctx, tr.tracer, "apiKeyRepository", "TouchAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return tr.next.TouchAPIKey(ctx, id)
+}
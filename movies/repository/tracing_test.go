@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/mock"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTracedMovieRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mock.NewMockMovieRespository(ctrl)
+	recorder := tracetest.NewSpanRecorder()
+	repo := NewTracedMovieRepository(next, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	next.EXPECT().IsMovieInCart(gomock.Any(), "1", "tt1375666").Return(true, nil)
+	next.EXPECT().ClearMovieCart(gomock.Any(), "1").Return(errors.New("connection reset"))
+
+	_, _ = repo.IsMovieInCart(context.Background(), "1", "tt1375666")
+	_ = repo.ClearMovieCart(context.Background(), "1")
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "movieRepository.IsMovieInCart", spans[0].Name())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Contains(t, spans[0].Attributes(), attribute.String("movie.id", "tt1375666"))
+		assert.Contains(t, spans[0].Attributes(), attribute.String("db.system", "postgresql"))
+
+		assert.Equal(t, "movieRepository.ClearMovieCart", spans[1].Name())
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+}
@@ -1,57 +1,339 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-movie-api/movies/apperror"
 	"go-movie-api/movies/model"
-	"log"
+	"log/slog"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// usersEmailConstraint is the unique key on users.email.
+const usersEmailConstraint = "users_email_key"
+
+const userColumns = `id, user_name, email, country, role, created_at, updated_at, deleted_at`
+
+// defaultUsersPageSize is the page size of a listing that doesn't ask for one.
+const defaultUsersPageSize = 20
+
+// userSortColumns maps the sorts of a user listing to their columns.
+var userSortColumns = map[string]string{"name": "user_name", "email": "email", "createdAt": "created_at"}
+
+var (
+	ErrUserAlreadyExists = apperror.Conflict("user_already_exists", "a user with this email already exists")
+	ErrUserNotFound      = apperror.NotFound("user_not_found", "user not found")
 )
 
 type UserRespository interface {
-	CreateUser(user model.CreateUserRequest) error
-	GetUsers() (users []model.User, err error)
+	CreateUser(ctx context.Context, user model.CreateUserRequest, passwordHash string) error
+	ListUsers(ctx context.Context, query model.ListUsersQuery) (users []model.User, total int, err error)
+	GetUser(ctx context.Context, userId string) (user model.User, err error)
+	GetUserByEmail(ctx context.Context, email string) (user model.User, err error)
+	UpdateUser(ctx context.Context, userId string, update model.UpdateUserRequest) (user model.User, err error)
+	DeleteUser(ctx context.Context, userId string) error
+	RestoreUser(ctx context.Context, userId string) (user model.User, err error)
+	GetUserCredentials(ctx context.Context, email string) (model.UserCredentials, error)
+	SetUserPassword(ctx context.Context, userId string, passwordHash string) error
+	GetUserRole(ctx context.Context, userId string) (role string, err error)
+	PromoteFirstAdmin(ctx context.Context, email string) (promoted bool, err error)
 }
 
 type userRespository struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	logger *slog.Logger
 }
 
-func NewUserRepository(db *sqlx.DB) userRespository {
-	return userRespository{db: db}
+func NewUserRepository(db *sqlx.DB, logger *slog.Logger) userRespository {
+	return userRespository{db: db, logger: logger}
 }
 
-func (mr userRespository) CreateUser(user model.CreateUserRequest) error {
-	_, err := mr.db.Exec(
-		"INSERT INTO users (user_name, email, country) VALUES ($1, $2, $3)",
-		user.Name, user.Email, user.Country,
+func (mr userRespository) CreateUser(ctx context.Context, user model.CreateUserRequest, passwordHash string) error {
+	_, err := mr.db.ExecContext(ctx,
+		"INSERT INTO users (user_name, email, country, password_hash) VALUES ($1, $2, $3, $4)",
+		user.Name, user.Email, user.Country, passwordHash,
 	)
 
 	if err != nil {
-		log.Println(err)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == usersEmailConstraint {
+			return ErrUserAlreadyExists
+		}
+		mr.logger.ErrorContext(ctx, "failed to create user", "error", err)
 		return err
 	}
 
 	return nil
 }
 
-func (mr userRespository) GetUsers() (result []model.User, err error) {
-	rows, err := mr.db.Query(`SELECT id, user_name, email, country, created_at, updated_at FROM users`)
+// ListUsers returns a page of the users that match query, and how many
+// match in all.
+func (mr userRespository) ListUsers(ctx context.Context, query model.ListUsersQuery) ([]model.User, int, error) {
+	var conditions []string
+	var args []any
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if !query.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if query.Country != "" {
+		where("country = $%d", query.Country)
+	}
+	if query.NamePrefix != "" {
+		where(`user_name ILIKE $%d`, likeEscaper.Replace(query.NamePrefix)+"%")
+	}
+	if !query.CreatedFrom.IsZero() {
+		where("created_at >= $%d", query.CreatedFrom)
+	}
+	if !query.CreatedTo.IsZero() {
+		where("created_at <= $%d", query.CreatedTo)
+	}
+	filter := ""
+	if len(conditions) > 0 {
+		filter = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := mr.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+filter, args...).Scan(&total); err != nil {
+		mr.logger.ErrorContext(ctx, "failed to count users", "error", err)
+		return nil, 0, err
+	}
+
+	sort, direction := strings.TrimPrefix(query.Sort, "-"), "ASC"
+	if strings.HasPrefix(query.Sort, "-") {
+		direction = "DESC"
+	}
+	column, ok := userSortColumns[sort]
+	if !ok {
+		column = "created_at"
+	}
+	pageSize := query.PageSize
+	if pageSize == 0 {
+		pageSize = defaultUsersPageSize
+	}
+	page := max(query.Page, 1)
+
+	rows, err := mr.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM users%s ORDER BY %s %s, id LIMIT %d OFFSET %d`, userColumns, filter, column, direction, pageSize, (page-1)*pageSize),
+		args...,
+	)
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		mr.logger.ErrorContext(ctx, "failed to list users", "error", err)
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var users []model.User
+	users := []model.User{}
 	for rows.Next() {
-		log.Println("Scan row:", err)
-		var user model.User
-		if err := rows.Scan(&user.UserId, &user.Name, &user.Email, &user.Country, &user.CreatedAt, &user.UpdatedAt); err != nil {
-			log.Println("Scan error:", err)
-			continue
+		user, err := scanUser(rows)
+		if err != nil {
+			mr.logger.ErrorContext(ctx, "failed to read user", "error", err)
+			return nil, 0, err
 		}
 		users = append(users, user)
 	}
+	if err := rows.Err(); err != nil {
+		mr.logger.ErrorContext(ctx, "failed to list users", "error", err)
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// GetUser looks a user up by id, deleted or not.
+func (mr userRespository) GetUser(ctx context.Context, userId string) (model.User, error) {
+	user, err := scanUser(mr.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, userId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to look up user", "error", err)
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail looks a user up by email, deleted or not.
+func (mr userRespository) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	user, err := scanUser(mr.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to look up user by email", "error", err)
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// UpdateUser sets the fields of update that aren't nil on a user that isn't
+// deleted. updated_at is kept by a trigger.
+func (mr userRespository) UpdateUser(ctx context.Context, userId string, update model.UpdateUserRequest) (model.User, error) {
+	var columns []string
+	args := []any{userId}
+	set := func(column string, value *string) {
+		if value != nil {
+			args = append(args, *value)
+			columns = append(columns, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+	}
+	set("user_name", update.Name)
+	set("email", update.Email)
+	set("country", update.Country)
+	if len(columns) == 0 {
+		return mr.getActiveUser(ctx, userId)
+	}
+
+	user, err := scanUser(mr.db.QueryRowContext(ctx,
+		`UPDATE users SET `+strings.Join(columns, ", ")+` WHERE id = $1 AND deleted_at IS NULL RETURNING `+userColumns,
+		args...,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == usersEmailConstraint {
+			return model.User{}, ErrUserAlreadyExists
+		}
+		mr.logger.ErrorContext(ctx, "failed to update user", "error", err)
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+func (mr userRespository) getActiveUser(ctx context.Context, userId string) (model.User, error) {
+	user, err := mr.GetUser(ctx, userId)
+	if err == nil && user.DeletedAt != nil {
+		return model.User{}, ErrUserNotFound
+	}
+	return user, err
+}
+
+// DeleteUser soft deletes a user: the row stays, so it can be restored.
+func (mr userRespository) DeleteUser(ctx context.Context, userId string) error {
+	result, err := mr.db.ExecContext(ctx, `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, userId)
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to delete user", "error", err)
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to delete user", "error", err)
+		return err
+	}
+	if deleted == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RestoreUser undoes DeleteUser.
+func (mr userRespository) RestoreUser(ctx context.Context, userId string) (model.User, error) {
+	user, err := scanUser(mr.db.QueryRowContext(ctx,
+		`UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING `+userColumns,
+		userId,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to restore user", "error", err)
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// GetUserCredentials looks a user that isn't deleted up by email.
+func (mr userRespository) GetUserCredentials(ctx context.Context, email string) (model.UserCredentials, error) {
+	var credentials model.UserCredentials
+	err := mr.db.QueryRowxContext(ctx,
+		`SELECT id, COALESCE(password_hash, '') AS password_hash, role FROM users WHERE email = $1 AND deleted_at IS NULL`,
+		email,
+	).StructScan(&credentials)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.UserCredentials{}, ErrUserNotFound
+	}
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to look up user credentials", "error", err)
+		return model.UserCredentials{}, err
+	}
+
+	return credentials, nil
+}
+
+// SetUserPassword replaces the password hash of a user that isn't deleted.
+func (mr userRespository) SetUserPassword(ctx context.Context, userId string, passwordHash string) error {
+	result, err := mr.db.ExecContext(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1 AND deleted_at IS NULL`, userId, passwordHash)
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to set user password", "error", err)
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to set user password", "error", err)
+		return err
+	}
+	if updated == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// GetUserRole returns the current role of a user that isn't deleted.
+func (mr userRespository) GetUserRole(ctx context.Context, userId string) (string, error) {
+	var role string
+	err := mr.db.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1 AND deleted_at IS NULL`, userId).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to look up user role", "error", err)
+		return "", err
+	}
+
+	return role, nil
+}
+
+// PromoteFirstAdmin makes the user with the given email an admin, as long as
+// there is no admin yet. It reports whether the user was promoted.
+func (mr userRespository) PromoteFirstAdmin(ctx context.Context, email string) (bool, error) {
+	result, err := mr.db.ExecContext(ctx,
+		`UPDATE users SET role = 'admin' WHERE email = $1 AND deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin' AND deleted_at IS NULL)`,
+		email,
+	)
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to promote the first admin", "error", err)
+		return false, err
+	}
+
+	promoted, err := result.RowsAffected()
+	if err != nil {
+		mr.logger.ErrorContext(ctx, "failed to promote the first admin", "error", err)
+		return false, err
+	}
+
+	return promoted == 1, nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
-	return users, nil
+func scanUser(row rowScanner) (model.User, error) {
+	var user model.User
+	err := row.Scan(&user.UserId, &user.Name, &user.Email, &user.Country, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	return user, err
 }
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/movies/logging"
+	"go-movie-api/movies/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserCredentials(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewUserRepository(db, logging.Discard())
+	query := regexp.QuoteMeta(`SELECT id, COALESCE(password_hash, '') AS password_hash, role FROM users WHERE email = $1 AND deleted_at IS NULL`)
+
+	t.Run("should return the user's id, password hash and role", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("jane@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "password_hash", "role"}).AddRow("123", "hash", "admin"))
+
+		credentials, err := repo.GetUserCredentials(context.Background(), "jane@example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "123", credentials.UserId)
+		assert.Equal(t, "hash", credentials.PasswordHash)
+		assert.Equal(t, "admin", credentials.Role)
+	})
+
+	t.Run("should return not found for an unknown email", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("nobody@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "password_hash", "role"}))
+
+		_, err := repo.GetUserCredentials(context.Background(), "nobody@example.com")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestSetUserPassword(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewUserRepository(db, logging.Discard())
+	update := regexp.QuoteMeta(`UPDATE users SET password_hash = $2 WHERE id = $1 AND deleted_at IS NULL`)
+
+	t.Run("should replace the password hash of the user", func(t *testing.T) {
+		mock.ExpectExec(update).WithArgs("123", "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetUserPassword(context.Background(), "123", "hash"))
+	})
+
+	t.Run("should return not found for a deleted user", func(t *testing.T) {
+		mock.ExpectExec(update).WithArgs("456", "hash").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetUserPassword(context.Background(), "456", "hash"), ErrUserNotFound)
+	})
+}
+
+func TestGetUserRole(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewUserRepository(db, logging.Discard())
+	query := regexp.QuoteMeta(`SELECT role FROM users WHERE id = $1 AND deleted_at IS NULL`)
+
+	t.Run("should return the user's role", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("123").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("admin"))
+
+		role, err := repo.GetUserRole(context.Background(), "123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", role)
+	})
+
+	t.Run("should return not found for a deleted user", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("456").WillReturnRows(sqlmock.NewRows([]string{"role"}))
+
+		_, err := repo.GetUserRole(context.Background(), "456")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestPromoteFirstAdmin(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewUserRepository(db, logging.Discard())
+	promote := regexp.QuoteMeta(`UPDATE users SET role = 'admin' WHERE email = $1 AND deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin' AND deleted_at IS NULL)`)
+
+	t.Run("should promote the user while there is no admin", func(t *testing.T) {
+		mock.ExpectExec(promote).WithArgs("jane@example.com").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		promoted, err := repo.PromoteFirstAdmin(context.Background(), "jane@example.com")
+
+		assert.NoError(t, err)
+		assert.True(t, promoted)
+	})
+
+	t.Run("should leave the user alone once there is an admin", func(t *testing.T) {
+		mock.ExpectExec(promote).WithArgs("jane@example.com").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		promoted, err := repo.PromoteFirstAdmin(context.Background(), "jane@example.com")
+
+		assert.NoError(t, err)
+		assert.False(t, promoted)
+	})
+}
+
+var userRowColumns = []string{"id", "user_name", "email", "country", "role", "created_at", "updated_at", "deleted_at"}
+
+func TestListUsers(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewUserRepository(db, logging.Discard())
+
+	t.Run("should return the first page of users that aren't deleted", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + userColumns + ` FROM users WHERE deleted_at IS NULL ORDER BY created_at ASC, id LIMIT 20 OFFSET 0`)).
+			WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("123", "Jane", "jane@example.com", "IN", "user", "2026-10-01T00:00:00Z", "2026-10-01T00:00:00Z", nil))
+
+		users, total, err := repo.ListUsers(context.Background(), model.ListUsersQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []model.User{{UserId: "123", Name: "Jane", Email: "jane@example.com", Country: "IN", Role: "user", CreatedAt: "2026-10-01T00:00:00Z", UpdatedAt: "2026-10-01T00:00:00Z"}}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should filter, sort and page the users", func(t *testing.T) {
+		from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, time.June, 30, 0, 0, 0, 0, time.UTC)
+		filter := ` FROM users WHERE country = $1 AND user_name ILIKE $2 AND created_at >= $3 AND created_at <= $4`
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*)`+filter)).WithArgs("IN", `ja\_%`, from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(45))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT `+userColumns+filter+` ORDER BY user_name DESC, id LIMIT 10 OFFSET 20`)).WithArgs("IN", `ja\_%`, from, to).
+			WillReturnRows(sqlmock.NewRows(userRowColumns))
+
+		users, total, err := repo.ListUsers(context.Background(), model.ListUsersQuery{
+			Country: "IN", NamePrefix: "ja_", CreatedFrom: from, CreatedTo: to, IncludeDeleted: true, Sort: "-name", Page: 3, PageSize: 10,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 45, total)
+		assert.Empty(t, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewUserRepository(db, logging.Discard())
+	name, email := "Janet", "janet@example.com"
+
+	t.Run("should set only the given fields of a user that isn't deleted", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET user_name = $2, email = $3 WHERE id = $1 AND deleted_at IS NULL RETURNING `+userColumns)).
+			WithArgs("123", name, email).
+			WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("123", name, email, "IN", "user", "2026-10-01T00:00:00Z", "2026-10-18T00:00:00Z", nil))
+
+		user, err := repo.UpdateUser(context.Background(), "123", model.UpdateUserRequest{Name: &name, Email: &email})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Janet", user.Name)
+		assert.Equal(t, "2026-10-18T00:00:00Z", user.UpdatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return already exists when the email is taken", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET email = $2`)).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: usersEmailConstraint})
+
+		_, err := repo.UpdateUser(context.Background(), "123", model.UpdateUserRequest{Email: &email})
+
+		assert.ErrorIs(t, err, ErrUserAlreadyExists)
+	})
+
+	t.Run("should return not found for a deleted user", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET user_name = $2`)).WillReturnRows(sqlmock.NewRows(userRowColumns))
+
+		_, err := repo.UpdateUser(context.Background(), "123", model.UpdateUserRequest{Name: &name})
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestDeleteAndRestoreUser(t *testing.T) {
+	db, mock, closeDb := setupMockDB(t)
+	defer closeDb()
+
+	repo := NewUserRepository(db, logging.Discard())
+	softDelete := regexp.QuoteMeta(`UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`)
+	restore := regexp.QuoteMeta(`UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING ` + userColumns)
+
+	t.Run("should soft delete a user", func(t *testing.T) {
+		mock.ExpectExec(softDelete).WithArgs("123").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.DeleteUser(context.Background(), "123"))
+	})
+
+	t.Run("should return not found when the user is already deleted", func(t *testing.T) {
+		mock.ExpectExec(softDelete).WithArgs("123").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.DeleteUser(context.Background(), "123"), ErrUserNotFound)
+	})
+
+	t.Run("should restore a deleted user", func(t *testing.T) {
+		mock.ExpectQuery(restore).WithArgs("123").
+			WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("123", "Jane", "jane@example.com", "IN", "user", "2026-10-01T00:00:00Z", "2026-10-18T00:00:00Z", nil))
+
+		user, err := repo.RestoreUser(context.Background(), "123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, user.DeletedAt)
+	})
+
+	t.Run("should return not found when the user isn't deleted", func(t *testing.T) {
+		mock.ExpectQuery(restore).WithArgs("456").WillReturnRows(sqlmock.NewRows(userRowColumns))
+
+		_, err := repo.RestoreUser(context.Background(), "456")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/repository"
+)
+
+type apiKeyService struct {
+	repository repository.APIKeyRepository
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, req model.CreateAPIKeyRequest) (resp model.CreateAPIKeyResponse, err error)
+	ListAPIKeys(ctx context.Context, userId string) (keys []model.APIKey, err error)
+	RevokeAPIKey(ctx context.Context, id string) (err error)
+	AuthenticateAPIKey(ctx context.Context, key string) (principal auth.Principal, err error)
+}
+
+func NewAPIKeyService(repository repository.APIKeyRepository) apiKeyService {
+	return apiKeyService{repository: repository}
+}
+
+func (as apiKeyService) CreateAPIKey(ctx context.Context, req model.CreateAPIKeyRequest) (model.CreateAPIKeyResponse, error) {
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return model.CreateAPIKeyResponse{}, err
+	}
+
+	created, err := as.repository.CreateAPIKey(ctx, model.APIKey{
+		UserID: req.UserID,
+		Name:   req.Name,
+		Prefix: key.Prefix,
+		Scopes: req.Scopes,
+	}, key.Hash)
+	if err != nil {
+		return model.CreateAPIKeyResponse{}, err
+	}
+
+	return model.CreateAPIKeyResponse{APIKey: created, Key: key.Value}, nil
+}
+
+func (as apiKeyService) ListAPIKeys(ctx context.Context, userId string) ([]model.APIKey, error) {
+	return as.repository.ListAPIKeys(ctx, userId)
+}
+
+func (as apiKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	return as.repository.RevokeAPIKey(ctx, id)
+}
+
+// AuthenticateAPIKey returns the principal a key acts as: its owner, with
+// the key's scopes and never more than the user role.
+func (as apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (auth.Principal, error) {
+	apiKey, err := as.repository.GetActiveAPIKey(ctx, auth.HashAPIKey(key))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	// a failed write of the last use shouldn't fail the request; the repository logs it
+	_ = as.repository.TouchAPIKey(ctx, apiKey.ID)
+
+	return auth.Principal{
+		UserID:   apiKey.UserID,
+		Role:     auth.RoleUser,
+		APIKeyID: apiKey.ID,
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
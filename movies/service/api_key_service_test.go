@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/repository"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	mock "go-movie-api/movies/mock"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockAPIKeyRepository(ctrl)
+	svc := NewAPIKeyService(mockRepo)
+	ctx := context.Background()
+
+	var storedHash string
+	mockRepo.EXPECT().
+		CreateAPIKey(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key model.APIKey, keyHash string) (model.APIKey, error) {
+			storedHash = keyHash
+			key.ID = "key-1"
+			return key, nil
+		})
+
+	resp, err := svc.CreateAPIKey(ctx, model.CreateAPIKeyRequest{UserID: "123", Name: "partner", Scopes: []string{auth.ScopeSearch}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", resp.ID)
+	assert.True(t, strings.HasPrefix(resp.Key, resp.Prefix))
+	assert.Equal(t, auth.HashAPIKey(resp.Key), storedHash)
+	assert.NotEqual(t, resp.Key, storedHash)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockAPIKeyRepository(ctrl)
+	svc := NewAPIKeyService(mockRepo)
+	ctx := context.Background()
+
+	t.Run("should act as the key's owner with its scopes", func(t *testing.T) {
+		mockRepo.EXPECT().GetActiveAPIKey(ctx, auth.HashAPIKey("mvk_key")).
+			Return(model.APIKey{ID: "key-1", UserID: "123", Scopes: []string{auth.ScopeCartRead}}, nil)
+		mockRepo.EXPECT().TouchAPIKey(ctx, "key-1").Return(errors.New("db failure"))
+
+		principal, err := svc.AuthenticateAPIKey(ctx, "mvk_key")
+
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Principal{UserID: "123", Role: auth.RoleUser, APIKeyID: "key-1", Scopes: []string{auth.ScopeCartRead}}, principal)
+		assert.True(t, principal.HasScope(auth.ScopeCartRead))
+		assert.False(t, principal.HasScope(auth.ScopeCartWrite))
+	})
+
+	t.Run("should reject an unknown or revoked key", func(t *testing.T) {
+		mockRepo.EXPECT().GetActiveAPIKey(ctx, auth.HashAPIKey("mvk_other")).Return(model.APIKey{}, repository.ErrAPIKeyNotFound)
+
+		_, err := svc.AuthenticateAPIKey(ctx, "mvk_other")
+
+		assert.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+	})
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/repository"
+)
+
+var ErrInvalidCredentials = apperror.Unauthorized("invalid_credentials", "invalid email or password")
+
+type authService struct {
+	users  repository.UserRespository
+	tokens repository.TokenRepository
+	issuer *auth.TokenManager
+}
+
+type AuthService interface {
+	Login(ctx context.Context, req model.LoginRequest) (resp model.TokenResponse, err error)
+	Refresh(ctx context.Context, req model.RefreshTokenRequest) (resp model.TokenResponse, err error)
+	Logout(ctx context.Context, req model.RefreshTokenRequest) (err error)
+}
+
+func NewAuthService(users repository.UserRespository, tokens repository.TokenRepository, issuer *auth.TokenManager) authService {
+	return authService{users: users, tokens: tokens, issuer: issuer}
+}
+
+// Login checks the user's password and starts a session. Unknown emails and
+// wrong passwords get the same error.
+func (as authService) Login(ctx context.Context, req model.LoginRequest) (model.TokenResponse, error) {
+	credentials, err := as.users.GetUserCredentials(ctx, req.Email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		auth.CheckPassword("", req.Password)
+		return model.TokenResponse{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	if !auth.CheckPassword(credentials.PasswordHash, req.Password) {
+		return model.TokenResponse{}, ErrInvalidCredentials
+	}
+
+	return as.startSession(ctx, credentials.UserId, credentials.Role)
+}
+
+// Refresh trades a refresh token for a new pair. Each refresh token works
+// once, so a stolen one stops working as soon as either party uses it.
+func (as authService) Refresh(ctx context.Context, req model.RefreshTokenRequest) (model.TokenResponse, error) {
+	claims, err := as.issuer.ParseRefresh(req.RefreshToken)
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	revoked, err := as.tokens.RevokeRefreshToken(ctx, claims.ID, claims.Subject)
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+	if !revoked {
+		return model.TokenResponse{}, auth.ErrInvalidToken
+	}
+
+	// the role is looked up again so that role changes apply from here on
+	role, err := as.users.GetUserRole(ctx, claims.Subject)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return model.TokenResponse{}, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	return as.startSession(ctx, claims.Subject, role)
+}
+
+// Logout revokes the refresh token. Access tokens already handed out stay
+// valid until they expire.
+func (as authService) Logout(ctx context.Context, req model.RefreshTokenRequest) error {
+	claims, err := as.issuer.ParseRefresh(req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = as.tokens.RevokeRefreshToken(ctx, claims.ID, claims.Subject)
+	return err
+}
+
+func (as authService) startSession(ctx context.Context, userId string, role string) (model.TokenResponse, error) {
+	access, err := as.issuer.IssueAccess(userId, role)
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	refresh, err := as.issuer.IssueRefresh(userId)
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	if err := as.tokens.SaveRefreshToken(ctx, refresh.ID, userId, refresh.ExpiresAt); err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	return model.TokenResponse{
+		AccessToken:  access.Value,
+		RefreshToken: refresh.Value,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(as.issuer.AccessTTL().Seconds()),
+	}, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/configs"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/model"
+	"go-movie-api/movies/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	mock "go-movie-api/movies/mock"
+)
+
+func newTestTokenManager(t *testing.T) *auth.TokenManager {
+	tokens, err := auth.NewTokenManager(configs.AuthConfig{
+		JwtSecret:              "test-secret-that-is-at-least-32-bytes",
+		Issuer:                 "go-movie-api",
+		AccessTokenTTLSeconds:  900,
+		RefreshTokenTTLSeconds: 3600,
+	})
+	assert.NoError(t, err)
+	return tokens
+}
+
+func TestLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mock.NewMockUserRespository(ctrl)
+	mockTokens := mock.NewMockTokenRepository(ctrl)
+	tokens := newTestTokenManager(t)
+	svc := NewAuthService(mockUsers, mockTokens, tokens)
+
+	ctx := context.Background()
+	hash, err := auth.HashPassword("correct horse", 4)
+	assert.NoError(t, err)
+
+	t.Run("should issue tokens for the right password", func(t *testing.T) {
+		mockUsers.EXPECT().GetUserCredentials(ctx, "jane@example.com").
+			Return(model.UserCredentials{UserId: "123", PasswordHash: hash, Role: auth.RoleUser}, nil)
+		mockTokens.EXPECT().SaveRefreshToken(ctx, gomock.Any(), "123", gomock.Any()).Return(nil)
+
+		resp, err := svc.Login(ctx, model.LoginRequest{Email: "jane@example.com", Password: "correct horse"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer", resp.TokenType)
+		assert.Equal(t, 900, resp.ExpiresIn)
+		claims, err := tokens.ParseAccess(resp.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "123", claims.Subject)
+		assert.Equal(t, auth.RoleUser, claims.Role)
+	})
+
+	t.Run("should reject the wrong password", func(t *testing.T) {
+		mockUsers.EXPECT().GetUserCredentials(ctx, "jane@example.com").
+			Return(model.UserCredentials{UserId: "123", PasswordHash: hash}, nil)
+
+		_, err := svc.Login(ctx, model.LoginRequest{Email: "jane@example.com", Password: "wrong horse"})
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("should reject an unknown email with the same error", func(t *testing.T) {
+		mockUsers.EXPECT().GetUserCredentials(ctx, "nobody@example.com").
+			Return(model.UserCredentials{}, repository.ErrUserNotFound)
+
+		_, err := svc.Login(ctx, model.LoginRequest{Email: "nobody@example.com", Password: "correct horse"})
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("should return repository errors", func(t *testing.T) {
+		mockUsers.EXPECT().GetUserCredentials(ctx, "jane@example.com").
+			Return(model.UserCredentials{}, errors.New("db failure"))
+
+		_, err := svc.Login(ctx, model.LoginRequest{Email: "jane@example.com", Password: "correct horse"})
+
+		assert.EqualError(t, err, "db failure")
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mock.NewMockUserRespository(ctrl)
+	mockTokens := mock.NewMockTokenRepository(ctrl)
+	tokens := newTestTokenManager(t)
+	svc := NewAuthService(mockUsers, mockTokens, tokens)
+
+	ctx := context.Background()
+	refresh, err := tokens.IssueRefresh("123")
+	assert.NoError(t, err)
+
+	t.Run("should rotate an active refresh token with the user's current role", func(t *testing.T) {
+		mockTokens.EXPECT().RevokeRefreshToken(ctx, refresh.ID, "123").Return(true, nil)
+		mockUsers.EXPECT().GetUserRole(ctx, "123").Return(auth.RoleAdmin, nil)
+		mockTokens.EXPECT().SaveRefreshToken(ctx, gomock.Any(), "123", gomock.Any()).Return(nil)
+
+		resp, err := svc.Refresh(ctx, model.RefreshTokenRequest{RefreshToken: refresh.Value})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, refresh.Value, resp.RefreshToken)
+		claims, err := tokens.ParseAccess(resp.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.RoleAdmin, claims.Role)
+	})
+
+	t.Run("should reject the refresh token of a deleted user", func(t *testing.T) {
+		mockTokens.EXPECT().RevokeRefreshToken(ctx, refresh.ID, "123").Return(true, nil)
+		mockUsers.EXPECT().GetUserRole(ctx, "123").Return("", repository.ErrUserNotFound)
+
+		_, err := svc.Refresh(ctx, model.RefreshTokenRequest{RefreshToken: refresh.Value})
+
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("should reject a refresh token that was already used", func(t *testing.T) {
+		mockTokens.EXPECT().RevokeRefreshToken(ctx, refresh.ID, "123").Return(false, nil)
+
+		_, err := svc.Refresh(ctx, model.RefreshTokenRequest{RefreshToken: refresh.Value})
+
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("should reject an access token", func(t *testing.T) {
+		access, err := tokens.IssueAccess("123", auth.RoleUser)
+		assert.NoError(t, err)
+
+		_, err = svc.Refresh(ctx, model.RefreshTokenRequest{RefreshToken: access.Value})
+
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTokens := mock.NewMockTokenRepository(ctrl)
+	tokens := newTestTokenManager(t)
+	svc := NewAuthService(mock.NewMockUserRespository(ctrl), mockTokens, tokens)
+
+	ctx := context.Background()
+	refresh, err := tokens.IssueRefresh("123")
+	assert.NoError(t, err)
+
+	mockTokens.EXPECT().RevokeRefreshToken(ctx, refresh.ID, "123").Return(false, nil)
+
+	err = svc.Logout(ctx, model.RefreshTokenRequest{RefreshToken: refresh.Value})
+
+	assert.NoError(t, err)
+}
@@ -1,32 +1,53 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"go-movie-api/movies/apperror"
 	"go-movie-api/movies/client"
 	"go-movie-api/movies/model"
 	"go-movie-api/movies/repository"
-	"log"
+	"go-movie-api/movies/tracing"
+	"go-movie-api/movies/validation"
+	"strconv"
 
-	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type movieService struct {
 	client     client.Client
 	repository repository.MovieRespository
+	tracer     trace.Tracer
 }
 
 type MovieService interface {
-	SearchMovies(ctx *gin.Context, req model.SearchMovieRequest) (resp []model.Movie, err error)
-	GetMovieDetails(ctx *gin.Context, req model.GetMovieDetailsRequest) (resp model.GetMovieDetailsResponse, err error)
-	AddMovieToCart(ctx *gin.Context, req model.AddMovieToCartRequest) (err error)
-	GetMoviesInCart(ctx *gin.Context, req model.GetMoviesInCartReq) (movies []model.MovieDetailsInCart, err error)
+	SearchMovies(ctx context.Context, req model.SearchMovieRequest) (resp []model.Movie, err error)
+	GetMovieDetails(ctx context.Context, req model.GetMovieDetailsRequest) (resp model.GetMovieDetailsResponse, err error)
+	AddMovieToCart(ctx context.Context, req model.AddMovieToCartRequest) (err error)
+	GetMoviesInCart(ctx context.Context, req model.GetMoviesInCartReq) (movies []model.MovieDetailsInCart, err error)
+	RemoveMovieFromCart(ctx context.Context, req model.RemoveMovieFromCartRequest) (err error)
+	ClearMovieCart(ctx context.Context, req model.ClearMovieCartRequest) (err error)
+	ReorderMovieCart(ctx context.Context, req model.ReorderMovieCartRequest) (err error)
 }
 
-func NewMovieService(client client.Client, repository repository.MovieRespository) movieService {
-	return movieService{client: client, repository: repository}
+func NewMovieService(client client.Client, repository repository.MovieRespository, tracerProvider trace.TracerProvider) movieService {
+	return movieService{client: client, repository: repository, tracer: tracing.Tracer(tracerProvider)}
 }
 
-func (ms movieService) SearchMovies(ctx *gin.Context, req model.SearchMovieRequest) (movies []model.Movie, err error) {
+func (ms movieService) SearchMovies(ctx context.Context, req model.SearchMovieRequest) (movies []model.Movie, err error) {
+	ctx, span := ms.tracer.Start(ctx, "movieService.SearchMovies", trace.WithAttributes(attribute.String("movie.query", req.SearchQuery)))
+	defer func() { tracing.End(span, err) }()
+
+	// the providers only filter by a single year, so a range searches every
+	// year and keeps the hits of the requested page that fall in the range.
+	// A page can come back short, or empty and answered as not found.
+	from, to, isRange := validation.YearRange(req.Year)
+	isRange = isRange && from != to
+	if isRange {
+		req.Year = ""
+	}
+
 	resp, err := ms.client.SearchMovies(ctx, req)
 
 	if err != nil {
@@ -34,13 +55,30 @@ func (ms movieService) SearchMovies(ctx *gin.Context, req model.SearchMovieReque
 	}
 
 	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+		return nil, movieNotFound(resp.Error)
 	}
 
-	return resp.Movies, nil
+	if !isRange {
+		return resp.Movies, nil
+	}
+	movies = make([]model.Movie, 0, len(resp.Movies))
+	for _, movie := range resp.Movies {
+		// series have years like 2010–2012, which count from their first year
+		year, err := strconv.Atoi(movie.Year[:min(4, len(movie.Year))])
+		if err == nil && year >= from && year <= to {
+			movies = append(movies, movie)
+		}
+	}
+	if len(movies) == 0 {
+		return nil, movieNotFound(fmt.Sprintf("no movies from %d to %d on this page", from, to))
+	}
+	return movies, nil
 }
 
-func (ms movieService) GetMovieDetails(ctx *gin.Context, req model.GetMovieDetailsRequest) (movieDetails model.GetMovieDetailsResponse, err error) {
+func (ms movieService) GetMovieDetails(ctx context.Context, req model.GetMovieDetailsRequest) (movieDetails model.GetMovieDetailsResponse, err error) {
+	ctx, span := ms.tracer.Start(ctx, "movieService.GetMovieDetails", trace.WithAttributes(attribute.String("movie.id", req.MovieID)))
+	defer func() { tracing.End(span, err) }()
+
 	resp, err := ms.client.GetMovieDetails(ctx, req)
 
 	if err != nil {
@@ -48,32 +86,89 @@ func (ms movieService) GetMovieDetails(ctx *gin.Context, req model.GetMovieDetai
 	}
 
 	if resp.Error != "" {
-		return model.GetMovieDetailsResponse{}, errors.New(resp.Error)
+		return model.GetMovieDetailsResponse{}, movieNotFound(resp.Error)
 	}
 
 	return resp, nil
 }
 
-func (ms movieService) AddMovieToCart(ctx *gin.Context, req model.AddMovieToCartRequest) (err error) {
+func (ms movieService) AddMovieToCart(ctx context.Context, req model.AddMovieToCartRequest) (err error) {
+	ctx, span := ms.tracer.Start(ctx, "movieService.AddMovieToCart", trace.WithAttributes(attribute.String("movie.id", req.MovieID), attribute.String("user.id", req.UserID)))
+	defer func() { tracing.End(span, err) }()
+
+	// check the user's own cart first so a duplicate doesn't cost an upstream call
+	inCart, err := ms.repository.IsMovieInCart(ctx, req.UserID, req.MovieID)
+	if err != nil {
+		return err
+	}
+
+	if inCart {
+		return repository.ErrMovieAlreadyInCart
+	}
+
 	resp, err := ms.client.GetMovieDetailsById(ctx, req)
 	if err != nil {
 		return err
 	}
 
-	if err := ms.repository.AddToMovieCart(resp, req.UserID); err != nil {
-		log.Println(err)
+	if resp.Error != "" {
+		return movieNotFound(resp.Error)
+	}
+
+	if err := ms.repository.AddToMovieCart(ctx, resp, req.UserID); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (ms movieService) GetMoviesInCart(ctx *gin.Context, req model.GetMoviesInCartReq) (movies []model.MovieDetailsInCart, err error) {
-	movies, dbErr := ms.repository.GetMoviesInCart(req.UserID)
+func (ms movieService) GetMoviesInCart(ctx context.Context, req model.GetMoviesInCartReq) (movies []model.MovieDetailsInCart, err error) {
+	ctx, span := ms.tracer.Start(ctx, "movieService.GetMoviesInCart", trace.WithAttributes(attribute.String("user.id", req.UserID)))
+	defer func() { tracing.End(span, err) }()
+
+	movies, dbErr := ms.repository.GetMoviesInCart(ctx, req.UserID)
 	if dbErr != nil {
-		log.Println(dbErr)
 		return nil, dbErr
 	}
 
 	return movies, nil
 }
+
+func (ms movieService) RemoveMovieFromCart(ctx context.Context, req model.RemoveMovieFromCartRequest) (err error) {
+	ctx, span := ms.tracer.Start(ctx, "movieService.RemoveMovieFromCart", trace.WithAttributes(attribute.String("movie.id", req.MovieID), attribute.String("user.id", req.UserID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := ms.repository.RemoveFromMovieCart(ctx, req.UserID, req.MovieID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ms movieService) ClearMovieCart(ctx context.Context, req model.ClearMovieCartRequest) (err error) {
+	ctx, span := ms.tracer.Start(ctx, "movieService.ClearMovieCart", trace.WithAttributes(attribute.String("user.id", req.UserID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := ms.repository.ClearMovieCart(ctx, req.UserID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ms movieService) ReorderMovieCart(ctx context.Context, req model.ReorderMovieCartRequest) (err error) {
+	ctx, span := ms.tracer.Start(ctx, "movieService.ReorderMovieCart", trace.WithAttributes(attribute.String("user.id", req.UserID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := ms.repository.ReorderMovieCart(ctx, req.UserID, req.MovieIDs); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// movieNotFound is the error for a provider response that carries an error
+// message instead of a movie; providers only do that when there is no match.
+func movieNotFound(message string) error {
+	return apperror.NotFound(client.ErrNotFound.Code, message)
+}
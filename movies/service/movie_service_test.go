@@ -1,26 +1,41 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"go-movie-api/movies/client"
 	"go-movie-api/movies/model"
+	"go-movie-api/movies/repository"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
 	mock "go-movie-api/movies/mock"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+type testContextKey struct{}
+
+// newTestContext returns a context for calling the service and a matcher for
+// the contexts it passes on, which are derived from it to carry its spans.
+func newTestContext() (context.Context, gomock.Matcher) {
+	marker := new(int)
+	ctx := context.WithValue(context.Background(), testContextKey{}, marker)
+	return ctx, gomock.Cond(func(c context.Context) bool {
+		return c.Value(testContextKey{}) == marker
+	})
+}
+
 func TestSearchMovies(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockClient := mock.NewMockClient(ctrl)
 	mockRepo := mock.NewMockMovieRespository(ctrl)
-	svc := NewMovieService(mockClient, mockRepo)
+	svc := NewMovieService(mockClient, mockRepo, noop.NewTracerProvider())
 
-	ctx := &gin.Context{}
+	ctx, inCtx := newTestContext()
 
 	t.Run("should return movies when api returns success", func(t *testing.T) {
 		req := model.SearchMovieRequest{SearchQuery: "Inception"}
@@ -29,7 +44,7 @@ func TestSearchMovies(t *testing.T) {
 			Error:  "",
 		}
 
-		mockClient.EXPECT().SearchMovies(ctx, req).Return(resp, nil)
+		mockClient.EXPECT().SearchMovies(inCtx, req).Return(resp, nil)
 
 		movies, err := svc.SearchMovies(ctx, req)
 
@@ -38,10 +53,55 @@ func TestSearchMovies(t *testing.T) {
 		assert.Equal(t, "Inception", movies[0].Title)
 	})
 
+	t.Run("should search every year and keep the movies in a year range", func(t *testing.T) {
+		req := model.SearchMovieRequest{SearchQuery: "Batman", Year: "2005-2010"}
+		resp := model.SearchMovieResponse{
+			Movies: []model.Movie{
+				{Title: "Batman Begins", Year: "2005"},
+				{Title: "Batman", Year: "1989"},
+				{Title: "The Dark Knight", Year: "2008"},
+				{Title: "Beware the Batman", Year: "2013–2014"},
+			},
+		}
+
+		mockClient.EXPECT().SearchMovies(inCtx, model.SearchMovieRequest{SearchQuery: "Batman"}).Return(resp, nil)
+
+		movies, err := svc.SearchMovies(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Movie{resp.Movies[0], resp.Movies[2]}, movies)
+	})
+
+	t.Run("should filter a year range within the requested page only", func(t *testing.T) {
+		req := model.SearchMovieRequest{SearchQuery: "Batman", Year: "2005-2010", Page: "2"}
+		resp := model.SearchMovieResponse{
+			Movies: []model.Movie{{Title: "Batman: The Killing Joke", Year: "2016"}, {Title: "Batman: Under the Red Hood", Year: "2010"}},
+		}
+
+		mockClient.EXPECT().SearchMovies(inCtx, model.SearchMovieRequest{SearchQuery: "Batman", Page: "2"}).Return(resp, nil)
+
+		movies, err := svc.SearchMovies(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Movie{resp.Movies[1]}, movies)
+	})
+
+	t.Run("should return not found when nothing on the page is in the year range", func(t *testing.T) {
+		req := model.SearchMovieRequest{SearchQuery: "Batman", Year: "2005-2010"}
+		resp := model.SearchMovieResponse{Movies: []model.Movie{{Title: "Batman", Year: "1989"}}}
+
+		mockClient.EXPECT().SearchMovies(inCtx, model.SearchMovieRequest{SearchQuery: "Batman"}).Return(resp, nil)
+
+		movies, err := svc.SearchMovies(ctx, req)
+
+		assert.Nil(t, movies)
+		assert.ErrorIs(t, err, client.ErrNotFound)
+	})
+
 	t.Run("should return client error when there is client failure", func(t *testing.T) {
 		req := model.SearchMovieRequest{SearchQuery: "Inception"}
 
-		mockClient.EXPECT().SearchMovies(ctx, req).Return(model.SearchMovieResponse{}, errors.New("client failure"))
+		mockClient.EXPECT().SearchMovies(inCtx, req).Return(model.SearchMovieResponse{}, errors.New("client failure"))
 
 		movies, err := svc.SearchMovies(ctx, req)
 
@@ -57,7 +117,7 @@ func TestSearchMovies(t *testing.T) {
 			Error:  "movie not found",
 		}
 
-		mockClient.EXPECT().SearchMovies(ctx, req).Return(resp, nil)
+		mockClient.EXPECT().SearchMovies(inCtx, req).Return(resp, nil)
 
 		movies, err := svc.SearchMovies(ctx, req)
 
@@ -73,8 +133,8 @@ func TestAddMovieToCart(t *testing.T) {
 
 	mockClient := mock.NewMockClient(ctrl)
 	mockRepo := mock.NewMockMovieRespository(ctrl)
-	svc := NewMovieService(mockClient, mockRepo)
-	ctx := &gin.Context{}
+	svc := NewMovieService(mockClient, mockRepo, noop.NewTracerProvider())
+	ctx, inCtx := newTestContext()
 
 	t.Run("should add movie to cart successfully", func(t *testing.T) {
 		req := model.AddMovieToCartRequest{
@@ -89,20 +149,47 @@ func TestAddMovieToCart(t *testing.T) {
 			ImdbID: "tt1375666",
 		}
 
-		mockClient.EXPECT().GetMovieDetailsById(ctx, req).Return(resp, nil)
-		mockRepo.EXPECT().AddToMovieCart(resp, req.UserID).Return(nil)
+		mockRepo.EXPECT().IsMovieInCart(inCtx, req.UserID, req.MovieID).Return(false, nil)
+		mockClient.EXPECT().GetMovieDetailsById(inCtx, req).Return(resp, nil)
+		mockRepo.EXPECT().AddToMovieCart(inCtx, resp, req.UserID).Return(nil)
 
 		err := svc.AddMovieToCart(ctx, req)
 		assert.NoError(t, err)
 	})
 
+	t.Run("should not call the api when the movie is already in the user's cart", func(t *testing.T) {
+		req := model.AddMovieToCartRequest{
+			UserID:  "123",
+			MovieID: "tt1375666",
+		}
+
+		mockRepo.EXPECT().IsMovieInCart(inCtx, req.UserID, req.MovieID).Return(true, nil)
+
+		err := svc.AddMovieToCart(ctx, req)
+		assert.ErrorIs(t, err, repository.ErrMovieAlreadyInCart)
+	})
+
+	t.Run("should return error when the movie is not found", func(t *testing.T) {
+		req := model.AddMovieToCartRequest{
+			UserID:  "123",
+			MovieID: "tt0000000",
+		}
+
+		mockRepo.EXPECT().IsMovieInCart(inCtx, req.UserID, req.MovieID).Return(false, nil)
+		mockClient.EXPECT().GetMovieDetailsById(inCtx, req).Return(model.GetMovieDetailsResponse{Error: "Incorrect IMDb ID."}, nil)
+
+		err := svc.AddMovieToCart(ctx, req)
+		assert.EqualError(t, err, "Incorrect IMDb ID.")
+	})
+
 	t.Run("should return error when client fails", func(t *testing.T) {
 		req := model.AddMovieToCartRequest{
 			UserID:  "123",
 			MovieID: "tt1375666",
 		}
 
-		mockClient.EXPECT().GetMovieDetailsById(ctx, req).Return(model.GetMovieDetailsResponse{}, errors.New("client error"))
+		mockRepo.EXPECT().IsMovieInCart(inCtx, req.UserID, req.MovieID).Return(false, nil)
+		mockClient.EXPECT().GetMovieDetailsById(inCtx, req).Return(model.GetMovieDetailsResponse{}, errors.New("client error"))
 
 		err := svc.AddMovieToCart(ctx, req)
 		assert.Error(t, err)
@@ -122,8 +209,9 @@ func TestAddMovieToCart(t *testing.T) {
 			ImdbID: "tt1375666",
 		}
 
-		mockClient.EXPECT().GetMovieDetailsById(ctx, req).Return(resp, nil)
-		mockRepo.EXPECT().AddToMovieCart(resp, req.UserID).Return(errors.New("repo error"))
+		mockRepo.EXPECT().IsMovieInCart(inCtx, req.UserID, req.MovieID).Return(false, nil)
+		mockClient.EXPECT().GetMovieDetailsById(inCtx, req).Return(resp, nil)
+		mockRepo.EXPECT().AddToMovieCart(inCtx, resp, req.UserID).Return(errors.New("repo error"))
 
 		err := svc.AddMovieToCart(ctx, req)
 		assert.Error(t, err)
@@ -138,8 +226,8 @@ func TestGetMovieDetails(t *testing.T) {
 	mockClient := mock.NewMockClient(ctrl)
 	mockRepo := mock.NewMockMovieRespository(ctrl)
 
-	svc := NewMovieService(mockClient, mockRepo)
-	ctx := &gin.Context{}
+	svc := NewMovieService(mockClient, mockRepo, noop.NewTracerProvider())
+	ctx, inCtx := newTestContext()
 
 	t.Run("should return movie details on api success", func(t *testing.T) {
 		req := model.GetMovieDetailsRequest{MovieID: "tt1375666"}
@@ -151,7 +239,7 @@ func TestGetMovieDetails(t *testing.T) {
 			Error:  "",
 		}
 
-		mockClient.EXPECT().GetMovieDetails(ctx, req).Return(resp, nil)
+		mockClient.EXPECT().GetMovieDetails(inCtx, req).Return(resp, nil)
 
 		result, err := svc.GetMovieDetails(ctx, req)
 
@@ -162,7 +250,7 @@ func TestGetMovieDetails(t *testing.T) {
 	t.Run("should return client error when there is client failure", func(t *testing.T) {
 		req := model.GetMovieDetailsRequest{MovieID: "tt1375666"}
 
-		mockClient.EXPECT().GetMovieDetails(ctx, req).Return(model.GetMovieDetailsResponse{}, errors.New("client error"))
+		mockClient.EXPECT().GetMovieDetails(inCtx, req).Return(model.GetMovieDetailsResponse{}, errors.New("client error"))
 
 		result, err := svc.GetMovieDetails(ctx, req)
 
@@ -177,7 +265,7 @@ func TestGetMovieDetails(t *testing.T) {
 			Error: "movie not found",
 		}
 
-		mockClient.EXPECT().GetMovieDetails(ctx, req).Return(resp, nil)
+		mockClient.EXPECT().GetMovieDetails(inCtx, req).Return(resp, nil)
 
 		result, err := svc.GetMovieDetails(ctx, req)
 
@@ -193,8 +281,8 @@ func TestGetMoviesInCart(t *testing.T) {
 
 	mockRepo := mock.NewMockMovieRespository(ctrl)
 	mockClient := mock.NewMockClient(ctrl)
-	svc := NewMovieService(mockClient, mockRepo)
-	ctx := &gin.Context{}
+	svc := NewMovieService(mockClient, mockRepo, noop.NewTracerProvider())
+	ctx, inCtx := newTestContext()
 
 	t.Run("should return movies from database", func(t *testing.T) {
 		req := model.GetMoviesInCartReq{UserID: "123"}
@@ -202,7 +290,7 @@ func TestGetMoviesInCart(t *testing.T) {
 			{Title: "Inception", Year: "2010", Genre: "Sci-Fi", ImdbID: "tt1375666"},
 		}
 
-		mockRepo.EXPECT().GetMoviesInCart(req.UserID).Return(expected, nil)
+		mockRepo.EXPECT().GetMoviesInCart(inCtx, req.UserID).Return(expected, nil)
 
 		movies, err := svc.GetMoviesInCart(ctx, req)
 
@@ -213,7 +301,7 @@ func TestGetMoviesInCart(t *testing.T) {
 	t.Run("should return db error when fetch fails", func(t *testing.T) {
 		req := model.GetMoviesInCartReq{UserID: "123"}
 
-		mockRepo.EXPECT().GetMoviesInCart(req.UserID).Return(nil, errors.New("db error"))
+		mockRepo.EXPECT().GetMoviesInCart(inCtx, req.UserID).Return(nil, errors.New("db error"))
 
 		movies, err := svc.GetMoviesInCart(ctx, req)
 
@@ -222,3 +310,87 @@ func TestGetMoviesInCart(t *testing.T) {
 		assert.Equal(t, "db error", err.Error())
 	})
 }
+
+func TestRemoveMovieFromCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockMovieRespository(ctrl)
+	mockClient := mock.NewMockClient(ctrl)
+	svc := NewMovieService(mockClient, mockRepo, noop.NewTracerProvider())
+	ctx, inCtx := newTestContext()
+
+	t.Run("should remove the movie from the user's cart", func(t *testing.T) {
+		req := model.RemoveMovieFromCartRequest{UserID: "123", MovieID: "tt1375666"}
+
+		mockRepo.EXPECT().RemoveFromMovieCart(inCtx, req.UserID, req.MovieID).Return(nil)
+
+		err := svc.RemoveMovieFromCart(ctx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return not in cart error when the movie was never added", func(t *testing.T) {
+		req := model.RemoveMovieFromCartRequest{UserID: "123", MovieID: "tt0000001"}
+
+		mockRepo.EXPECT().RemoveFromMovieCart(inCtx, req.UserID, req.MovieID).Return(repository.ErrMovieNotInCart)
+
+		err := svc.RemoveMovieFromCart(ctx, req)
+		assert.ErrorIs(t, err, repository.ErrMovieNotInCart)
+	})
+}
+
+func TestClearMovieCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockMovieRespository(ctrl)
+	mockClient := mock.NewMockClient(ctrl)
+	svc := NewMovieService(mockClient, mockRepo, noop.NewTracerProvider())
+	ctx, inCtx := newTestContext()
+
+	t.Run("should clear the user's cart", func(t *testing.T) {
+		req := model.ClearMovieCartRequest{UserID: "123"}
+
+		mockRepo.EXPECT().ClearMovieCart(inCtx, req.UserID).Return(nil)
+
+		err := svc.ClearMovieCart(ctx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return db error when clearing fails", func(t *testing.T) {
+		req := model.ClearMovieCartRequest{UserID: "123"}
+
+		mockRepo.EXPECT().ClearMovieCart(inCtx, req.UserID).Return(errors.New("db error"))
+
+		err := svc.ClearMovieCart(ctx, req)
+		assert.EqualError(t, err, "db error")
+	})
+}
+
+func TestReorderMovieCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockMovieRespository(ctrl)
+	mockClient := mock.NewMockClient(ctrl)
+	svc := NewMovieService(mockClient, mockRepo, noop.NewTracerProvider())
+	ctx, inCtx := newTestContext()
+
+	t.Run("should reorder the user's cart", func(t *testing.T) {
+		req := model.ReorderMovieCartRequest{UserID: "123", MovieIDs: []string{"tt0816692", "tt1375666"}}
+
+		mockRepo.EXPECT().ReorderMovieCart(inCtx, req.UserID, req.MovieIDs).Return(nil)
+
+		err := svc.ReorderMovieCart(ctx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return invalid order error when ids do not match the cart", func(t *testing.T) {
+		req := model.ReorderMovieCartRequest{UserID: "123", MovieIDs: []string{"tt1375666"}}
+
+		mockRepo.EXPECT().ReorderMovieCart(inCtx, req.UserID, req.MovieIDs).Return(repository.ErrInvalidCartOrder)
+
+		err := svc.ReorderMovieCart(ctx, req)
+		assert.ErrorIs(t, err, repository.ErrInvalidCartOrder)
+	})
+}
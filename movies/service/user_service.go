@@ -1,26 +1,62 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/auth"
 	"go-movie-api/movies/model"
 	"go-movie-api/movies/repository"
-	"log"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrPasswordTooLong is a password longer than the 72 bytes bcrypt hashes.
+// The binding counts characters, so multibyte passwords can get this far.
+var ErrPasswordTooLong = &apperror.Error{
+	Kind:   apperror.KindValidation,
+	Code:   "invalid_request",
+	Detail: "password must have at most 72 bytes",
+	Fields: []model.FieldError{{Field: "password", Rule: "max", Message: "must have at most 72 bytes"}},
+}
+
+// ErrWrongPassword is a password change whose current password doesn't match.
+var ErrWrongPassword = &apperror.Error{
+	Kind:   apperror.KindValidation,
+	Code:   "wrong_password",
+	Detail: "the current password is wrong",
+	Fields: []model.FieldError{{Field: "currentPassword", Rule: "password", Message: "is wrong"}},
+}
+
 type userService struct {
-	repository repository.UserRespository
+	repository   repository.UserRespository
+	passwordCost int
 }
 
 type UserService interface {
-	CreateUser(req model.CreateUserRequest) (err error)
-	GetUsers() (users []model.User, err error)
+	CreateUser(ctx context.Context, req model.CreateUserRequest) (err error)
+	ListUsers(ctx context.Context, query model.ListUsersQuery) (users []model.User, total int, err error)
+	GetUser(ctx context.Context, userId string) (user model.User, err error)
+	GetUserByEmail(ctx context.Context, email string) (user model.User, err error)
+	UpdateUser(ctx context.Context, userId string, req model.UpdateUserRequest) (user model.User, err error)
+	DeleteUser(ctx context.Context, userId string) (err error)
+	RestoreUser(ctx context.Context, userId string) (user model.User, err error)
+	ChangePassword(ctx context.Context, userId string, req model.ChangePasswordRequest) (err error)
+	ResetPassword(ctx context.Context, userId string, req model.ResetPasswordRequest) (err error)
 }
 
-func NewUserService(repository repository.UserRespository) userService {
-	return userService{repository: repository}
+// NewUserService stores passwords as bcrypt hashes of the given cost.
+func NewUserService(repository repository.UserRespository, passwordCost int) userService {
+	return userService{repository: repository, passwordCost: passwordCost}
 }
 
-func (ms userService) CreateUser(req model.CreateUserRequest) (err error) {
-	dbErr := ms.repository.CreateUser(req)
+func (ms userService) CreateUser(ctx context.Context, req model.CreateUserRequest) (err error) {
+	passwordHash, err := ms.hashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	dbErr := ms.repository.CreateUser(ctx, req, passwordHash)
 	if dbErr != nil {
 		return dbErr
 	}
@@ -28,12 +64,69 @@ func (ms userService) CreateUser(req model.CreateUserRequest) (err error) {
 	return nil
 }
 
-func (ms userService) GetUsers() (users []model.User, err error) {
-	users, dbErr := ms.repository.GetUsers()
-	if dbErr != nil {
-		log.Println(dbErr)
-		return nil, dbErr
+func (ms userService) ListUsers(ctx context.Context, query model.ListUsersQuery) (users []model.User, total int, err error) {
+	return ms.repository.ListUsers(ctx, query)
+}
+
+func (ms userService) GetUser(ctx context.Context, userId string) (user model.User, err error) {
+	return ms.repository.GetUser(ctx, userId)
+}
+
+func (ms userService) GetUserByEmail(ctx context.Context, email string) (user model.User, err error) {
+	return ms.repository.GetUserByEmail(ctx, email)
+}
+
+// UpdateUser changes the fields of req that are set.
+func (ms userService) UpdateUser(ctx context.Context, userId string, req model.UpdateUserRequest) (user model.User, err error) {
+	return ms.repository.UpdateUser(ctx, userId, req)
+}
+
+// DeleteUser soft deletes a user. They can no longer log in, refresh their
+// tokens or use their API keys, until RestoreUser brings them back.
+func (ms userService) DeleteUser(ctx context.Context, userId string) (err error) {
+	return ms.repository.DeleteUser(ctx, userId)
+}
+
+func (ms userService) RestoreUser(ctx context.Context, userId string) (user model.User, err error) {
+	return ms.repository.RestoreUser(ctx, userId)
+}
+
+// ChangePassword sets a user's own password, once they have proven they know
+// the current one.
+func (ms userService) ChangePassword(ctx context.Context, userId string, req model.ChangePasswordRequest) (err error) {
+	user, err := ms.repository.GetUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	credentials, err := ms.repository.GetUserCredentials(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if credentials.PasswordHash != "" && !auth.CheckPassword(credentials.PasswordHash, req.CurrentPassword) {
+		return ErrWrongPassword
 	}
 
-	return users, nil
+	return ms.setPassword(ctx, userId, req.Password)
+}
+
+// ResetPassword is an admin setting a user's password, for when they have
+// forgotten it or never had one.
+func (ms userService) ResetPassword(ctx context.Context, userId string, req model.ResetPasswordRequest) (err error) {
+	return ms.setPassword(ctx, userId, req.Password)
+}
+
+func (ms userService) setPassword(ctx context.Context, userId string, password string) error {
+	passwordHash, err := ms.hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return ms.repository.SetUserPassword(ctx, userId, passwordHash)
+}
+
+func (ms userService) hashPassword(password string) (string, error) {
+	passwordHash, err := auth.HashPassword(password, ms.passwordCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", ErrPasswordTooLong.Wrap(err)
+	}
+	return passwordHash, err
 }
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/auth"
+	"go-movie-api/movies/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	mock "go-movie-api/movies/mock"
+)
+
+func TestCreateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mock.NewMockUserRespository(ctrl)
+	svc := NewUserService(mockUsers, 4)
+	ctx := context.Background()
+
+	t.Run("should store a hash of the password", func(t *testing.T) {
+		req := model.CreateUserRequest{Name: "Jane", Email: "jane@example.com", Country: "IN", Password: "correct horse"}
+		mockUsers.EXPECT().CreateUser(ctx, req, gomock.Not("correct horse")).Return(nil)
+
+		assert.NoError(t, svc.CreateUser(ctx, req))
+	})
+
+	t.Run("should reject a password of 72 characters but more than 72 bytes", func(t *testing.T) {
+		req := model.CreateUserRequest{Name: "Jane", Email: "jane@example.com", Country: "IN", Password: strings.Repeat("é", 72)}
+
+		err := svc.CreateUser(ctx, req)
+
+		appErr := apperror.From(err)
+		assert.Equal(t, apperror.KindValidation, appErr.Kind)
+		assert.Equal(t, []model.FieldError{{Field: "password", Rule: "max", Message: "must have at most 72 bytes"}}, appErr.Fields)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mock.NewMockUserRespository(ctrl)
+	svc := NewUserService(mockUsers, 4)
+	ctx := context.Background()
+	currentHash, _ := auth.HashPassword("old password", 4)
+	user := model.User{UserId: "123", Email: "jane@example.com"}
+
+	t.Run("should store a hash of the new password when the current one matches", func(t *testing.T) {
+		mockUsers.EXPECT().GetUser(ctx, "123").Return(user, nil)
+		mockUsers.EXPECT().GetUserCredentials(ctx, "jane@example.com").Return(model.UserCredentials{UserId: "123", PasswordHash: currentHash}, nil)
+		mockUsers.EXPECT().SetUserPassword(ctx, "123", gomock.Not("new password")).Return(nil)
+
+		assert.NoError(t, svc.ChangePassword(ctx, "123", model.ChangePasswordRequest{CurrentPassword: "old password", Password: "new password"}))
+	})
+
+	t.Run("should refuse when the current password is wrong", func(t *testing.T) {
+		mockUsers.EXPECT().GetUser(ctx, "123").Return(user, nil)
+		mockUsers.EXPECT().GetUserCredentials(ctx, "jane@example.com").Return(model.UserCredentials{UserId: "123", PasswordHash: currentHash}, nil)
+
+		err := svc.ChangePassword(ctx, "123", model.ChangePasswordRequest{CurrentPassword: "guess", Password: "new password"})
+
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+
+	t.Run("should not ask a user without a password for the current one", func(t *testing.T) {
+		mockUsers.EXPECT().GetUser(ctx, "123").Return(user, nil)
+		mockUsers.EXPECT().GetUserCredentials(ctx, "jane@example.com").Return(model.UserCredentials{UserId: "123"}, nil)
+		mockUsers.EXPECT().SetUserPassword(ctx, "123", gomock.Any()).Return(nil)
+
+		assert.NoError(t, svc.ChangePassword(ctx, "123", model.ChangePasswordRequest{Password: "new password"}))
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mock.NewMockUserRespository(ctrl)
+	svc := NewUserService(mockUsers, 4)
+	ctx := context.Background()
+
+	t.Run("should store a hash of the password", func(t *testing.T) {
+		mockUsers.EXPECT().SetUserPassword(ctx, "123", gomock.Not("new password")).Return(nil)
+
+		assert.NoError(t, svc.ResetPassword(ctx, "123", model.ResetPasswordRequest{Password: "new password"}))
+	})
+
+	t.Run("should reject a password of more than 72 bytes", func(t *testing.T) {
+		err := svc.ResetPassword(ctx, "123", model.ResetPasswordRequest{Password: strings.Repeat("é", 72)})
+
+		assert.Equal(t, []model.FieldError{{Field: "password", Rule: "max", Message: "must have at most 72 bytes"}}, apperror.From(err).Fields)
+	})
+}
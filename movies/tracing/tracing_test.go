@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"go-movie-api/configs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewProvider(t *testing.T) {
+	for _, exporter := range []string{"none", "stdout", "otlp"} {
+		provider, err := NewProvider(context.Background(), configs.TracingConfig{Exporter: exporter, ServiceName: "test", SamplePercent: 100})
+		if assert.NoError(t, err, exporter) {
+			assert.NoError(t, provider.Shutdown(context.Background()))
+		}
+	}
+
+	_, err := NewProvider(context.Background(), configs.TracingConfig{Exporter: "zipkin"})
+	assert.EqualError(t, err, `unknown tracing exporter "zipkin", expected none, stdout or otlp`)
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := Tracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	_, span := tracer.Start(context.Background(), "ok")
+	End(span, nil)
+	_, span = tracer.Start(context.Background(), "failed")
+	End(span, errors.New("boom"))
+
+	ended := recorder.Ended()
+	if assert.Len(t, ended, 2) {
+		assert.Equal(t, codes.Unset, ended[0].Status().Code)
+		assert.Equal(t, codes.Error, ended[1].Status().Code)
+		assert.Equal(t, "boom", ended[1].Status().Description)
+		assert.Len(t, ended[1].Events(), 1)
+	}
+}
@@ -0,0 +1,198 @@
+// Package validation adds the rules the request models use in their binding
+// tags to gin's validator, and turns failed validations into field-level
+// error details.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/constants"
+	"go-movie-api/movies/model"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// MovieTypes are the types of title the providers know.
+var MovieTypes = []string{"movie", "series", "episode"}
+
+const (
+	// MovieIDPattern matches IMDb ids and the ids of TMDB search hits, which
+	// have no IMDb id; see tmdbId in the client package.
+	MovieIDPattern   = `^(tt\d+|tmdb:(movie|tv):\d+)$`
+	YearPattern      = `^\d{4}$`
+	YearRangePattern = `^\d{4}(-\d{4})?$`
+	CountryPattern   = `^[A-Z]{2}$`
+)
+
+var (
+	movieID   = regexp.MustCompile(MovieIDPattern)
+	year      = regexp.MustCompile(YearPattern)
+	yearRange = regexp.MustCompile(YearRangePattern)
+)
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+)
+
+// Register adds the custom rules to gin's validator and names fields after
+// their json, form or uri keys in errors. It only does so once.
+func Register() error {
+	registerOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			registerErr = errors.New("gin's validator is not go-playground/validator")
+			return
+		}
+		registerErr = register(v)
+	})
+	return registerErr
+}
+
+func register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+
+	rules := map[string]validator.Func{
+		"searchtext": func(fl validator.FieldLevel) bool {
+			return utf8.RuneCountInString(strings.TrimSpace(fl.Field().String())) >= constants.MinSearchLength
+		},
+		"year": func(fl validator.FieldLevel) bool {
+			return year.MatchString(fl.Field().String())
+		},
+		"yearrange": func(fl validator.FieldLevel) bool {
+			from, to, ok := YearRange(fl.Field().String())
+			return ok && from <= to
+		},
+		"movietype": func(fl validator.FieldLevel) bool {
+			return slices.Contains(MovieTypes, fl.Field().String())
+		},
+		"movieid": func(fl validator.FieldLevel) bool {
+			return movieID.MatchString(fl.Field().String())
+		},
+	}
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			return err
+		}
+	}
+	v.RegisterAlias("country", "iso3166_1_alpha2")
+	return nil
+}
+
+// YearRange reads a year, like 2010, or a range of years, like 2010-2015.
+// A single year is a range of one year.
+func YearRange(value string) (from, to int, ok bool) {
+	if !yearRange.MatchString(value) {
+		return 0, 0, false
+	}
+	first, last, isRange := strings.Cut(value, "-")
+	if !isRange {
+		last = first
+	}
+	from, _ = strconv.Atoi(first)
+	to, _ = strconv.Atoi(last)
+	return from, to, true
+}
+
+// fieldName is the name clients know a field by.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Error is the error for a request that failed to bind: field-level details
+// when it broke validation rules, or else the reason it couldn't be read.
+func Error(err error) error {
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return apperror.Validation("invalid_request", err.Error()).Wrap(err)
+	}
+
+	fields := make([]model.FieldError, 0, len(invalid))
+	messages := make([]string, 0, len(invalid))
+	for _, fieldErr := range invalid {
+		field := model.FieldError{Field: fieldPath(fieldErr), Rule: fieldErr.Tag(), Message: message(fieldErr)}
+		fields = append(fields, field)
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+
+	appErr := apperror.Validation("invalid_request", strings.Join(messages, "; ")).Wrap(err)
+	appErr.Fields = fields
+	return appErr
+}
+
+// fieldPath is the field's path below the request, e.g. movieIds[1].
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+	return path
+}
+
+func message(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required when " + jsonName(param) + " is not set"
+	case "searchtext":
+		return fmt.Sprintf("must be at least %d characters", constants.MinSearchLength)
+	case "email":
+		return "must be an email address"
+	case "year":
+		return "must be a year, like 2010"
+	case "yearrange":
+		return "must be a year, like 2010, or a range of years, like 2010-2015"
+	case "movietype":
+		return "must be one of " + strings.Join(MovieTypes, ", ")
+	case "movieid":
+		return "must be an IMDb id, like tt1375666, or a TMDB id, like tmdb:movie:27205"
+	case "country", "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code, like IN"
+	case "uuid":
+		return "must be a uuid"
+	case "number":
+		return "must be a whole number"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "max", "len":
+		unit := "characters"
+		if kind := fieldErr.Kind(); kind == reflect.Slice || kind == reflect.Map {
+			unit = "items"
+		}
+		bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fieldErr.Tag()]
+		return fmt.Sprintf("must have %s %s %s", bound, param, unit)
+	default:
+		return "is invalid"
+	}
+}
+
+// jsonName guesses the json name of the struct field a rule like
+// required_without refers to, which it names by its Go name: MovieID is
+// movieId, as the models spell ids.
+func jsonName(goName string) string {
+	if goName == "" {
+		return goName
+	}
+	name := strings.ToLower(goName[:1]) + goName[1:]
+	if base, ok := strings.CutSuffix(name, "ID"); ok && base != "" {
+		name = base + "Id"
+	}
+	return name
+}
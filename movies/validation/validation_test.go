@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"errors"
+	"go-movie-api/movies/apperror"
+	"go-movie-api/movies/model"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	assert.NoError(t, Register())
+
+	tests := []struct {
+		name  string
+		req   any
+		valid bool
+	}{
+		{"search text of three characters", model.SearchMovieRequest{SearchQuery: "Up!"}, true},
+		{"search text that is too short", model.SearchMovieRequest{SearchQuery: " ab "}, false},
+		{"year", model.SearchMovieRequest{SearchQuery: "Batman", Year: "2010"}, true},
+		{"year range", model.SearchMovieRequest{SearchQuery: "Batman", Year: "2005-2010"}, true},
+		{"backwards year range", model.SearchMovieRequest{SearchQuery: "Batman", Year: "2010-2005"}, false},
+		{"year that isn't one", model.SearchMovieRequest{SearchQuery: "Batman", Year: "10"}, false},
+		{"known type", model.SearchMoviesQuery{Query: "Batman", Type: "series"}, true},
+		{"unknown type", model.SearchMoviesQuery{Query: "Batman", Type: "game"}, false},
+		{"imdb id", model.MovieURI{ImdbID: "tt1375666"}, true},
+		{"imdb id without digits", model.MovieURI{ImdbID: "tt"}, false},
+		{"tmdb id of a movie", model.MovieURI{ImdbID: "tmdb:movie:27205"}, true},
+		{"tmdb id of a series", model.AddMovieToCartRequest{MovieID: "tmdb:tv:1399"}, true},
+		{"tmdb id of an unknown media type", model.MovieURI{ImdbID: "tmdb:person:287"}, false},
+		{"imdb ids of a reorder", model.ReorderMovieCartRequest{MovieIDs: []string{"tt1375666", "nm0634240"}}, false},
+		{"range in a details lookup", model.GetMovieDetailsRequest{MovieID: "tt1375666", Year: "2005-2010"}, false},
+		{"details lookup by title", model.GetMovieDetailsRequest{Title: "Inception"}, true},
+		{"details lookup by id", model.GetMovieDetailsRequest{MovieID: "tt1375666"}, true},
+		{"details lookup by title and a bad id", model.GetMovieDetailsRequest{Title: "Inception", MovieID: "1375666"}, false},
+		{"details lookup of nothing", model.GetMovieDetailsRequest{}, false},
+		{"email", model.LoginRequest{Email: "jane@example.com", Password: "secret"}, true},
+		{"email without a domain", model.LoginRequest{Email: "jane", Password: "secret"}, false},
+		{"country", model.CreateUserRequest{Name: "Jane", Email: "jane@example.com", Country: "IN", Password: "password1"}, true},
+		{"country that isn't a code", model.CreateUserRequest{Name: "Jane", Email: "jane@example.com", Country: "India", Password: "password1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(tt.req)
+
+			assert.Equal(t, tt.valid, err == nil, "error: %v", err)
+		})
+	}
+}
+
+func TestYearRange(t *testing.T) {
+	from, to, ok := YearRange("2005-2010")
+	assert.True(t, ok)
+	assert.Equal(t, 2005, from)
+	assert.Equal(t, 2010, to)
+
+	from, to, ok = YearRange("2010")
+	assert.True(t, ok)
+	assert.Equal(t, 2010, from)
+	assert.Equal(t, 2010, to)
+
+	_, _, ok = YearRange("2010-")
+	assert.False(t, ok)
+}
+
+func TestError(t *testing.T) {
+	assert.NoError(t, Register())
+
+	t.Run("should list the fields that broke a rule by their json names", func(t *testing.T) {
+		err := Error(binding.Validator.ValidateStruct(model.ReorderMovieCartRequest{MovieIDs: []string{"tt1375666", "1375666"}}))
+
+		appErr := apperror.From(err)
+		assert.Equal(t, apperror.KindValidation, appErr.Kind)
+		assert.Equal(t, "invalid_request", appErr.Code)
+		assert.Equal(t, "movieIds[1] must be an IMDb id, like tt1375666, or a TMDB id, like tmdb:movie:27205", appErr.Detail)
+		assert.Equal(t, []model.FieldError{{Field: "movieIds[1]", Rule: "movieid", Message: "must be an IMDb id, like tt1375666, or a TMDB id, like tmdb:movie:27205"}}, appErr.Fields)
+	})
+
+	t.Run("should name the other field of a required_without by its json name", func(t *testing.T) {
+		err := Error(binding.Validator.ValidateStruct(model.GetMovieDetailsRequest{}))
+
+		assert.Equal(t, []model.FieldError{
+			{Field: "title", Rule: "required_without", Message: "is required when movieId is not set"},
+			{Field: "movieId", Rule: "required_without", Message: "is required when title is not set"},
+		}, apperror.From(err).Fields)
+	})
+
+	t.Run("should report a body that can't be read without fields", func(t *testing.T) {
+		err := Error(errors.New("unexpected EOF"))
+
+		appErr := apperror.From(err)
+		assert.Equal(t, "invalid_request", appErr.Code)
+		assert.Equal(t, "unexpected EOF", appErr.Detail)
+		assert.Empty(t, appErr.Fields)
+	})
+}